@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	riotkitorgv1alpha1 "github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/bmg"
+	"github.com/riotkit-org/backup-maker-controller/pkg/repository"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"strings"
+	"time"
+)
+
+func NewPruneCommand() *cobra.Command {
+	app := PruneApp{}
+	command := &cobra.Command{
+		Use:   "prune",
+		Short: "Deletes versions of a Backup Repository collection, that are not kept by the retention policy",
+		Long: "Applies .spec.retention of a ScheduledBackup. Runs in a Job created by the controller, when Backup Repository is not accessible by the controller itself. " +
+			"Backup Repository URL, access token and collection are read from " + bmg.EnvRepositoryURL + ", " + bmg.EnvRepositoryToken + " and " + bmg.EnvRepositoryCollectionId +
+			" environment variables, $VAR and ${VAR} references in their values are expanded",
+		Run: func(command *cobra.Command, args []string) {
+			err := app.Run(command.Context(), command.OutOrStdout())
+
+			if err != nil {
+				logrus.Errorf(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+
+	command.Flags().IntVarP(&app.policy.KeepLast, "keep-last", "", 0, "Keep N newest versions")
+	command.Flags().IntVarP(&app.policy.KeepDaily, "keep-daily", "", 0, "Keep the newest version of each of N last days")
+	command.Flags().IntVarP(&app.policy.KeepWeekly, "keep-weekly", "", 0, "Keep the newest version of each of N last weeks")
+	command.Flags().IntVarP(&app.policy.KeepMonthly, "keep-monthly", "", 0, "Keep the newest version of each of N last months")
+	command.Flags().DurationVarP(&app.maxAge, "max-age", "", 0, "Delete versions older than given duration, also those kept by --keep-* rules")
+	command.Flags().BoolVarP(&app.policy.DryRun, "dry-run", "", false, "Only print versions, that would be deleted")
+	command.Flags().StringSliceVarP(&app.pinned, "pin", "", []string{}, "Keep given versions e.g. v3, that are going to be restored")
+
+	return command
+}
+
+type PruneApp struct {
+	policy riotkitorgv1alpha1.RetentionSpec
+	maxAge time.Duration
+	pinned []string
+}
+
+func (a *PruneApp) Run(ctx context.Context, out io.Writer) error {
+	if a.maxAge > 0 {
+		a.policy.MaxAge = &metav1.Duration{Duration: a.maxAge}
+	}
+	if !a.policy.HasKeepRules() && a.policy.MaxAge == nil {
+		return errors.New("no retention rules given, use at least one of --keep-* or --max-age")
+	}
+	url := os.ExpandEnv(os.Getenv(bmg.EnvRepositoryURL))
+	collectionId := os.ExpandEnv(os.Getenv(bmg.EnvRepositoryCollectionId))
+	if url == "" || collectionId == "" {
+		return errors.Errorf("%s and %s must be set", bmg.EnvRepositoryURL, bmg.EnvRepositoryCollectionId)
+	}
+
+	client := repository.NewClient(url, os.ExpandEnv(os.Getenv(bmg.EnvRepositoryToken)))
+	plan, err := repository.ApplyRetention(ctx, client, collectionId, a.policy, a.pinned, time.Now())
+	printRetentionPlan(out, plan, a.policy.DryRun)
+	return err
+}
+
+// printRetentionPlan is the report of the retention Job, visible in its logs
+func printRetentionPlan(out io.Writer, plan repository.RetentionPlan, dryRun bool) {
+	for _, kept := range plan.Kept {
+		_, _ = fmt.Fprintf(out, "keep          %s  %s  (%s)\n", kept.GetName(), kept.CreatedAt.UTC().Format(time.RFC3339), strings.Join(kept.Reasons, ", "))
+	}
+	removedPrefix, summary := "remove      ", "kept %d versions, removed %d\n"
+	if dryRun {
+		removedPrefix, summary = "would remove", "dry run: kept %d versions, %d would be removed\n"
+	}
+	for _, removed := range plan.Removed {
+		_, _ = fmt.Fprintf(out, "%s  %s  %s\n", removedPrefix, removed.GetName(), removed.CreatedAt.UTC().Format(time.RFC3339))
+	}
+	_, _ = fmt.Fprintf(out, summary, len(plan.Kept), len(plan.Removed))
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/bmg"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPruneApp_Run(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer jwt-from-pod", r.Header.Get("Authorization"))
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": true, "data": {"versions": [
+			{"id": "aaa", "versionNumber": 1, "createdAt": "2023-01-01T02:00:00Z"},
+			{"id": "bbb", "versionNumber": 2, "createdAt": "2023-01-02T02:00:00Z"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	// values are references to the Pod's environment, the same as in .Repository section of vars
+	t.Setenv("REPOSITORY_URL", server.URL)
+	t.Setenv("REPOSITORY_TOKEN", "jwt-from-pod")
+	t.Setenv(bmg.EnvRepositoryURL, "${REPOSITORY_URL}")
+	t.Setenv(bmg.EnvRepositoryToken, "$REPOSITORY_TOKEN")
+	t.Setenv(bmg.EnvRepositoryCollectionId, "iwa-ait")
+
+	app := PruneApp{}
+	app.policy.KeepLast = 1
+	out := bytes.NewBufferString("")
+
+	assert.Nil(t, app.Run(context.TODO(), out))
+	assert.Equal(t, []string{"/api/alpha/repository/collection/iwa-ait/version/aaa"}, deleted)
+	assert.Contains(t, out.String(), "keep          v2  2023-01-02T02:00:00Z  (newest, last)")
+	assert.Contains(t, out.String(), "remove        v1  2023-01-01T02:00:00Z")
+	assert.Contains(t, out.String(), "kept 1 versions, removed 1")
+
+	//
+	// ASSERT: a version pinned by a restore is not deleted
+	//
+	deleted = nil
+	app = PruneApp{pinned: []string{"v1"}}
+	app.policy.KeepLast = 1
+	out = bytes.NewBufferString("")
+
+	assert.Nil(t, app.Run(context.TODO(), out))
+	assert.Empty(t, deleted)
+	assert.Contains(t, out.String(), "keep          v1  2023-01-01T02:00:00Z  (restoring)")
+}
+
+func TestPruneApp_Run_RequiresRules(t *testing.T) {
+	app := PruneApp{}
+	assert.ErrorContains(t, app.Run(context.TODO(), bytes.NewBufferString("")), "no retention rules given")
+}
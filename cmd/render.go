@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	riotkitorgv1alpha1 "github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/bmg"
+	"github.com/riotkit-org/backup-maker-controller/pkg/factory"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/fs"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func NewRenderCommand() *cobra.Command {
+	app := RenderApp{}
+	command := &cobra.Command{
+		Use:   "render",
+		Short: "Renders ScheduledBackup objects from files into Kubernetes manifests, without connecting to the cluster",
+		Long: "Reads ScheduledBackup, ClusterBackupProcedureTemplate, BackupProcedureTemplate and Secret manifests from files " +
+			"and prints CronJob, Job, Secret and ConfigMap objects that the controller would apply",
+		Run: func(command *cobra.Command, args []string) {
+			err := app.Run(command.Context(), command.OutOrStdout())
+
+			if err != nil {
+				logrus.Errorf(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+
+	command.Flags().StringSliceVarP(&app.filenames, "filename", "f", []string{}, "Files containing ScheduledBackup and all objects it references (can be specified multiple times)")
+	command.Flags().StringVarP(&app.namespace, "namespace", "n", "default", "Namespace for objects that do not specify it")
+	command.Flags().StringVarP(&app.resourcesDir, "resources-dir", "", filepath.Join(os.TempDir(), "backup-maker-generator"), "Writable directory, where templates and Helm chart of Backup Maker Generator are extracted")
+	command.Flags().BoolVarP(&app.debug, "debug", "v", false, "Increase verbosity to the debug level")
+	_ = command.MarkFlagRequired("filename")
+
+	return command
+}
+
+type RenderApp struct {
+	filenames    []string
+	namespace    string
+	resourcesDir string
+	debug        bool
+}
+
+func (a *RenderApp) Run(ctx context.Context, out io.Writer) error {
+	// logs are going to the stderr, the stdout is reserved for rendered manifests
+	logrus.SetLevel(logrus.WarnLevel)
+	if a.debug {
+		logrus.SetLevel(logrus.DebugLevel)
+	}
+
+	objects, err := readManifests(a.filenames, a.namespace)
+	if err != nil {
+		return err
+	}
+	resources, err := bmg.ExtractResources(a.resourcesDir)
+	if err != nil {
+		return err
+	}
+	return renderScheduledBackups(ctx, out, resources, objects)
+}
+
+// readManifests is decoding all objects from multi-document YAML files
+func readManifests(filenames []string, defaultNamespace string) ([]client.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+
+	var objects []client.Object
+	for _, filename := range filenames {
+		content, readErr := os.ReadFile(filename)
+		if readErr != nil {
+			return nil, errors.Wrapf(readErr, "cannot read file '%s'", filename)
+		}
+
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+		for {
+			doc, docErr := reader.Read()
+			if docErr == io.EOF {
+				break
+			}
+			if docErr != nil {
+				return nil, errors.Wrapf(docErr, "cannot read YAML document from '%s'", filename)
+			}
+			if len(bytes.TrimSpace(doc)) == 0 {
+				continue
+			}
+
+			obj, _, decodeErr := decoder.Decode(doc, nil, nil)
+			if decodeErr != nil {
+				return nil, errors.Wrapf(decodeErr, "cannot decode object from '%s'", filename)
+			}
+			clientObj, ok := obj.(client.Object)
+			if !ok {
+				return nil, errors.Errorf("unsupported object in '%s': %s", filename, obj.GetObjectKind().GroupVersionKind().String())
+			}
+			objects = append(objects, normalizeObject(clientObj, defaultNamespace))
+		}
+	}
+	return objects, nil
+}
+
+// normalizeObject is doing what the API server would do on creation, so the objects could be used by a fake client
+func normalizeObject(obj client.Object, defaultNamespace string) client.Object {
+	if _, isClusterScoped := obj.(*riotkitorgv1alpha1.ClusterBackupProcedureTemplate); !isClusterScoped && obj.GetNamespace() == "" {
+		obj.SetNamespace(defaultNamespace)
+	}
+	if secret, isSecret := obj.(*v1.Secret); isSecret {
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		for key, value := range secret.StringData {
+			secret.Data[key] = []byte(value)
+		}
+		secret.StringData = nil
+	}
+	return obj
+}
+
+// renderScheduledBackups is running the same pipeline as ScheduledBackupReconciler, but against a fake client and prints the result instead of applying
+func renderScheduledBackups(ctx context.Context, out io.Writer, resources fs.FS, objects []client.Object) error {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	fetcher := factory.CachedFetcher{Cache: fakeClient}
+
+	rendered := 0
+	for _, obj := range objects {
+		backup, isBackup := obj.(*riotkitorgv1alpha1.ScheduledBackup)
+		if !isBackup {
+			continue
+		}
+		logger := logrus.WithFields(logrus.Fields{"name": backup.Name, "namespace": backup.Namespace})
+
+		aggregate, _, hydrateErr := factory.NewFactory(fakeClient, fetcher, logger).CreateScheduledBackupAggregate(ctx, backup, "")
+		if hydrateErr != nil {
+			return errors.Wrapf(hydrateErr, "cannot resolve objects referenced by ScheduledBackup '%s/%s'", backup.Namespace, backup.Name)
+		}
+		docs, renderErr := bmg.RenderKubernetesResourcesFor(logger, resources, aggregate)
+		if renderErr != nil {
+			return errors.Wrapf(renderErr, "cannot render ScheduledBackup '%s/%s'", backup.Namespace, backup.Name)
+		}
+
+		for _, doc := range docs {
+			doc.SetNamespace(backup.Namespace)
+			asYaml, marshalErr := yaml.Marshal(doc.Object)
+			if marshalErr != nil {
+				return errors.Wrapf(marshalErr, "cannot serialize %s '%s'", doc.GetKind(), doc.GetName())
+			}
+			if _, writeErr := fmt.Fprintf(out, "---\n%s", asYaml); writeErr != nil {
+				return errors.Wrap(writeErr, "cannot write rendered manifests")
+			}
+		}
+		rendered += 1
+	}
+
+	if rendered == 0 {
+		return errors.New("no ScheduledBackup found in given files")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/bmg"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderScheduledBackups_Examples(t *testing.T) {
+	objects, err := readManifests([]string{
+		"../examples/scheduledbackup.yaml",
+		"../examples/pg13.yaml",
+		"../examples/backup-keys.secret.yaml",
+	}, "default")
+	assert.Nil(t, err)
+	assert.Len(t, objects, 3)
+
+	resources, err := bmg.ExtractResources(t.TempDir())
+	assert.Nil(t, err)
+
+	out := bytes.NewBufferString("")
+	assert.Nil(t, renderScheduledBackups(context.TODO(), out, resources, objects))
+
+	assert.Contains(t, out.String(), "kind: CronJob")
+	assert.Contains(t, out.String(), "name: app1-backup")
+	assert.Contains(t, out.String(), "name: app1-restore")
+	assert.Contains(t, out.String(), "--password 'this is a test'", "Expected Params.password to be imported from the Secret's stringData")
+}
+
+func TestRenderScheduledBackups_MissingTemplate(t *testing.T) {
+	objects, err := readManifests([]string{
+		"../examples/scheduledbackup.yaml",
+		"../examples/backup-keys.secret.yaml",
+	}, "default")
+	assert.Nil(t, err)
+
+	resources, err := bmg.ExtractResources(t.TempDir())
+	assert.Nil(t, err)
+
+	renderErr := renderScheduledBackups(context.TODO(), bytes.NewBufferString(""), resources, objects)
+	assert.NotNil(t, renderErr)
+	assert.Contains(t, renderErr.Error(), "cannot fetch ClusterBackupProcedureTemplate type object")
+}
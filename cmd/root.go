@@ -2,23 +2,29 @@ package cmd
 
 import (
 	riotkitorgv1alpha1 "github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/bmg"
 	"github.com/riotkit-org/backup-maker-controller/pkg/client/clientset/versioned/typed/riotkit/v1alpha1"
 	controllers2 "github.com/riotkit-org/backup-maker-controller/pkg/controllers"
 	"github.com/riotkit-org/backup-maker-controller/pkg/factory"
 	"github.com/riotkit-org/backup-maker-controller/pkg/integration"
 	"github.com/riotkit-org/backup-maker-controller/pkg/locking"
+	"github.com/riotkit-org/backup-maker-controller/pkg/notification"
+	"github.com/riotkit-org/backup-maker-controller/pkg/webhooks"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"os"
+	"path/filepath"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"time"
 )
 
 func NewRootCommand() *cobra.Command {
@@ -36,6 +42,9 @@ func NewRootCommand() *cobra.Command {
 		},
 	}
 
+	command.AddCommand(NewRenderCommand())
+	command.AddCommand(NewPruneCommand())
+
 	command.Flags().BoolVarP(&app.debug, "debug", "v", true, "Increase verbosity to the debug level")
 	command.Flags().StringVarP(&app.metricsBindAddress, "metrics-bind-address", "m", ":8080", "Host + Port on which to bind metrics endpoint to")
 	command.Flags().StringVarP(&app.healthProbeBindAddress, "health-probe-bind-address", "p", ":8081", "Host + Port on which to bind healthcheck endpoint to")
@@ -44,6 +53,13 @@ func NewRootCommand() *cobra.Command {
 	command.Flags().StringVarP(&app.redisHost, "redis-host", "", "redis", "Redis hostname or IP address")
 	command.Flags().IntVarP(&app.redisPort, "redis-port", "", 6379, "Redis port number")
 	command.Flags().BoolVarP(&app.disableRedis, "disable-redis", "", false, "Disable redis and use in-memory locking mechanism (does not work for multiple instances of the controller)")
+	command.Flags().StringVarP(&app.resourcesDir, "resources-dir", "", filepath.Join(os.TempDir(), "backup-maker-generator"), "Writable directory, where templates and Helm chart of Backup Maker Generator are extracted on startup")
+	command.Flags().BoolVarP(&app.enableWebhooks, "enable-webhooks", "", false, "Serve defaulting and validating admission webhooks. Requires a TLS certificate in the webhook server's certificate directory")
+	command.Flags().StringVarP(&app.conflictPolicy, "apply-conflict-policy", "", string(bmg.ConflictPolicyForce), "What to do, when applied fields are owned by someone else: 'force' - take over the fields, 'fail' - do not apply. Conflicts are always reported as Warning events")
+	command.Flags().StringSliceVarP(&app.integrations, "integrations", "", integration.AdapterNames(), "Integrations used to run and observe backup & restore actions. Integrations of kinds not installed in the cluster are skipped")
+	command.Flags().IntVarP(&app.versionsLimit, "repository-versions-limit", "", 10, "Number of the newest versions from Backup Repository published in ScheduledBackup's .status.repository. Set to 0 to not contact Backup Repository")
+	command.Flags().DurationVarP(&app.versionsSyncInterval, "repository-sync-interval", "", time.Minute*15, "How often the versions are listed from Backup Repository. Those are listed also after each successful backup")
+	command.Flags().StringVarP(&app.retentionJobImage, "retention-job-image", "", "", "Image of this controller, used by Jobs applying ScheduledBackup's .spec.retention when Backup Repository is not accessible by the controller. Such Jobs are not created, when empty")
 
 	return command
 }
@@ -57,6 +73,13 @@ type App struct {
 	redisHost              string
 	redisPort              int
 	disableRedis           bool
+	conflictPolicy         string
+	resourcesDir           string
+	enableWebhooks         bool
+	integrations           []string
+	versionsLimit          int
+	versionsSyncInterval   time.Duration
+	retentionJobImage      string
 }
 
 var (
@@ -74,6 +97,15 @@ func (a *App) Run() error {
 	if a.debug {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
+	conflictPolicy, err := bmg.ParseConflictPolicy(a.conflictPolicy)
+	if err != nil {
+		return err
+	}
+
+	resources, err := bmg.ExtractResources(a.resourcesDir)
+	if err != nil {
+		return err
+	}
 
 	var locker locking.Locker
 	if a.disableRedis {
@@ -117,8 +149,21 @@ func (a *App) Run() error {
 	if clErr != nil {
 		panic(clErr.Error())
 	}
-	integrations := integration.NewAllSupportedJobResourceTypes(kubeconfig)
+	mapper, clErr := bmg.NewRESTMapper(kubeconfig)
+	if clErr != nil {
+		panic(clErr.Error())
+	}
+	discoveryClient, clErr := discovery.NewDiscoveryClientForConfig(kubeconfig)
+	if clErr != nil {
+		panic(clErr.Error())
+	}
+	integrations, err := integration.NewAllSupportedJobResourceTypes(kubeconfig, discoveryClient, a.integrations, logrus.WithField("component", "integrations"))
+	if err != nil {
+		setupLog.Error(err, "unable to set up integrations")
+		return err
+	}
 	fetcher := factory.CachedFetcher{Cache: mgr.GetCache(), Client: brClient}
+	notifier := notification.NewSender(mgr.GetCache())
 
 	if err = (&controllers2.ClusterBackupProcedureTemplateReconciler{
 		Client: mgr.GetClient(),
@@ -128,30 +173,46 @@ func (a *App) Run() error {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterBackupProcedureTemplate")
 		return err
 	}
+	if err = (&controllers2.BackupProcedureTemplateReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Cache:  mgr.GetCache(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupProcedureTemplate")
+		return err
+	}
 	if err = (&controllers2.ScheduledBackupReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		Cache:     mgr.GetCache(),
-		BRClient:  brClient,
-		RestCfg:   kubeconfig,
-		DynClient: dynClient,
-		Fetcher:   factory.CachedFetcher{Cache: mgr.GetCache(), Client: brClient},
-		Recorder:  recorder,
-		Locker:    locker,
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Cache:      mgr.GetCache(),
+		BRClient:   brClient,
+		RESTMapper: mapper,
+		Resources:  resources,
+		DynClient:  dynClient,
+		Fetcher:    factory.CachedFetcher{Cache: mgr.GetCache(), Client: brClient},
+		Recorder:   recorder,
+		Locker:     locker,
+
+		ConflictPolicy: conflictPolicy,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScheduledBackup")
 		return err
 	}
 	if err = (&controllers2.RequestedBackupActionReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		Cache:     mgr.GetCache(),
-		BRClient:  brClient,
-		DynClient: dynClient,
-		RestCfg:   kubeconfig,
-		Fetcher:   fetcher,
-		Recorder:  recorder,
-		Locker:    locker,
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Cache:      mgr.GetCache(),
+		BRClient:   brClient,
+		DynClient:  dynClient,
+		RESTMapper: mapper,
+		Resources:  resources,
+		Fetcher:    fetcher,
+		Recorder:   recorder,
+		Locker:     locker,
+
+		Integrations:                     &integrations,
+		ConflictPolicy:                   conflictPolicy,
+		RestoreTargetsValidatedByWebhook: a.enableWebhooks,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RequestedBackupAction")
 		return err
@@ -163,6 +224,8 @@ func (a *App) Run() error {
 		BRClient:     brClient,
 		Client:       mgr.GetClient(),
 		Locker:       locker,
+		Recorder:     recorder,
+		Notifier:     notifier,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "JobsManagedByRequestedBackupActionObserver")
 		return err
@@ -173,10 +236,25 @@ func (a *App) Run() error {
 		BRClient:     brClient,
 		Client:       mgr.GetClient(),
 		Locker:       locker,
+		Recorder:     recorder,
+		Notifier:     notifier,
+
+		RepositoryVersionsLimit: a.versionsLimit,
+		RepositorySyncInterval:  a.versionsSyncInterval,
+		RetentionJobImage:       a.retentionJobImage,
+		RESTMapper:              mapper,
+		DynClient:               dynClient,
+		Resources:               resources,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "JobsManagedByScheduledBackupObserver")
 		return err
 	}
+	if a.enableWebhooks {
+		if err = webhooks.SetupWithManager(mgr, integrations.GetRunnableKinds()); err != nil {
+			setupLog.Error(err, "unable to create webhooks")
+			return err
+		}
+	}
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
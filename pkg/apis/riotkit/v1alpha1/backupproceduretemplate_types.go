@@ -0,0 +1,77 @@
+/*
+Copyright 2022 Riotkit.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupProcedureTemplateSpec defines the desired state of BackupProcedureTemplate
+type BackupProcedureTemplateSpec struct {
+	Image   string `json:"image"`
+	Backup  string `json:"backup"`
+	Restore string `json:"restore"`
+}
+
+// BackupProcedureTemplateStatus defines the observed state of BackupProcedureTemplate
+type BackupProcedureTemplateStatus struct {
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// BackupProcedureTemplate is the Schema for the backupproceduretemplates API.
+// Works the same way as ClusterBackupProcedureTemplate, but is visible only to ScheduledBackups in the same namespace
+type BackupProcedureTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupProcedureTemplateSpec   `json:"spec,omitempty"`
+	Status BackupProcedureTemplateStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// BackupProcedureTemplateList contains a list of BackupProcedureTemplate
+type BackupProcedureTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupProcedureTemplate `json:"items"`
+}
+
+func (bpt *BackupProcedureTemplate) GetImage() string {
+	return bpt.Spec.Image
+}
+
+func (bpt *BackupProcedureTemplate) GetBackupScript() string {
+	return bpt.Spec.Backup
+}
+
+func (bpt *BackupProcedureTemplate) GetRestoreScript() string {
+	return bpt.Spec.Restore
+}
+
+func (bpt *BackupProcedureTemplate) ProvidesScript() bool {
+	return true
+}
+
+func (bpt *BackupProcedureTemplate) GetName() string {
+	return bpt.Name
+}
@@ -10,6 +10,9 @@ import (
 
 const LabelTrackingId = "riotkit.org/job-tracking-id"
 
+// AnnotationOwner points to the owner of an object placed in other namespace than its owner, as `kind/namespace/name`
+const AnnotationOwner = "riotkit.org/owner"
+
 type ChildReference struct {
 	// API version of the referent.
 	APIVersion string `json:"apiVersion" protobuf:"bytes,5,opt,name=apiVersion"`
@@ -20,13 +23,25 @@ type ChildReference struct {
 	// Instead we will be using a label and a unique ID
 	// TrackingId is the execution id. Every created object is getting its unique id
 	TrackingId string `json:"trackingId" protobuf:"bytes,4,opt,name=trackingId"`
+	// Name of the referent. Empty when the object was created using "generateName"
+	Name string `json:"name,omitempty" protobuf:"bytes,3,opt,name=name"`
+	// Namespace of the referent. Empty, when the referent is in the namespace of its parent
+	Namespace string `json:"namespace,omitempty" protobuf:"bytes,6,opt,name=namespace"`
+}
+
+// GetNamespace is returning the namespace of the referent, defaulting to the parent's namespace
+func (cr *ChildReference) GetNamespace(parentNamespace string) string {
+	if cr.Namespace == "" {
+		return parentNamespace
+	}
+	return cr.Namespace
 }
 
 func (cr *ChildReference) GetGVK() schema.GroupVersionKind {
 	split := strings.Split(cr.APIVersion, "/")
 	var group string
 	var version string
-	if len(split) == 0 {
+	if len(split) == 1 {
 		group = ""
 		version = split[0]
 	} else {
@@ -43,22 +58,47 @@ func (cr *ChildReference) GetGVK() schema.GroupVersionKind {
 type ChildrenReferences []ChildReference
 
 // AddOwnedObject is adding object to the list of children references, so the parent object could have a complete list
-// of all its children
-func AddOwnedObject(ref *ChildrenReferences, doc *unstructured.Unstructured) {
+// of all its children. Namespace is recorded only for objects placed outside the parent's namespace
+func AddOwnedObject(ref *ChildrenReferences, doc *unstructured.Unstructured, parentNamespace string) {
 	labels := doc.GetLabels()
 	if _, ok := labels[LabelTrackingId]; !ok {
 		logrus.Warnf("Cannot find label '%s' for object", LabelTrackingId)
 		return
 	}
 	gvk := doc.GroupVersionKind()
-	*ref = append(*ref, ChildReference{
+	child := ChildReference{
 		APIVersion: gvk.Group + "/" + gvk.Version,
 		Kind:       gvk.Kind,
 		TrackingId: labels[LabelTrackingId],
-	})
+		Name:       doc.GetName(),
+	}
+	if doc.GetNamespace() != parentNamespace {
+		child.Namespace = doc.GetNamespace()
+	}
+	*ref = append(*ref, child)
+}
+
+// Matches tells if both references are pointing to the same object - by the tracking id,
+// or by the kind and name for objects that are not using "generateName"
+func (cr *ChildReference) Matches(other ChildReference) bool {
+	if cr.TrackingId == other.TrackingId {
+		return true
+	}
+	return cr.Name != "" && cr.Name == other.Name && cr.Namespace == other.Namespace && cr.GetGVK() == other.GetGVK()
 }
 
-// AppendJobIdTo is setting a label that marks a resource with a unique id
+// Contains tells if the list has a reference pointing to the same object
+func (in ChildrenReferences) Contains(ref ChildReference) bool {
+	for _, existing := range in {
+		if existing.Matches(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// AppendJobIdTo is setting a label that marks a resource with a unique id.
+// Jobs spawned by a CronJob are getting the same label, so those could be found without listing all Jobs in the namespace
 func AppendJobIdTo(doc *unstructured.Unstructured) {
 	labels := doc.GetLabels()
 	if len(labels) == 0 {
@@ -67,4 +107,8 @@ func AppendJobIdTo(doc *unstructured.Unstructured) {
 	id := uuid.New()
 	labels[LabelTrackingId] = id.String()
 	doc.SetLabels(labels)
+
+	if doc.GetKind() == "CronJob" {
+		_ = unstructured.SetNestedField(doc.Object, id.String(), "spec", "jobTemplate", "metadata", "labels", LabelTrackingId)
+	}
 }
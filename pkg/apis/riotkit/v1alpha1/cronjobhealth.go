@@ -0,0 +1,119 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+)
+
+// CronJobRunsHistoryLimit is a number of last runs kept in the .status.cronJob.lastRuns
+const CronJobRunsHistoryLimit = 10
+
+// RunOutcome is a result of a single Job spawned by a CronJob
+// +kubebuilder:validation:Enum=Running;Succeeded;Failed;TimedOut
+type RunOutcome string
+
+const (
+	RunRunning   RunOutcome = "Running"
+	RunSucceeded RunOutcome = "Succeeded"
+	RunFailed    RunOutcome = "Failed"
+	RunTimedOut  RunOutcome = "TimedOut"
+)
+
+// IsFinished tells if the run will not change its outcome anymore
+func (o RunOutcome) IsFinished() bool {
+	return o != RunRunning
+}
+
+// CronJobRun is a single Job spawned by a CronJob
+type CronJobRun struct {
+	JobName        string       `json:"jobName"`
+	Outcome        RunOutcome   `json:"outcome"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// CronJobStatus is a history of CronJob executions. Kubernetes keeps only a few last Jobs, so the history
+// and the consecutive failures counter are persisted there
+type CronJobStatus struct {
+	LastScheduleTime    *metav1.Time `json:"lastScheduleTime,omitempty"`
+	LastSuccessfulTime  *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	// LastRuns are sorted from the newest
+	LastRuns []CronJobRun `json:"lastRuns,omitempty"`
+}
+
+// Record is merging runs observed on the cluster into the history. Each finished run is counted only once.
+// Returns runs that finished since the previous observation
+func (in *CronJobStatus) Record(observed CronJobStatus) []CronJobRun {
+	var finished []CronJobRun
+	if observed.LastScheduleTime != nil {
+		in.LastScheduleTime = observed.LastScheduleTime
+	}
+	if observed.LastSuccessfulTime != nil {
+		in.LastSuccessfulTime = observed.LastSuccessfulTime
+	}
+
+	// from the oldest, so the consecutive failures are counted in order
+	runs := append([]CronJobRun{}, observed.LastRuns...)
+	sortRunsFromNewest(runs)
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		previous := in.findRun(run.JobName)
+		if previous == nil {
+			in.LastRuns = append(in.LastRuns, run)
+		} else {
+			if previous.Outcome.IsFinished() {
+				continue
+			}
+			*previous = run
+		}
+
+		if run.Outcome.IsFinished() {
+			finished = append(finished, run)
+		}
+		if run.Outcome == RunSucceeded {
+			in.ConsecutiveFailures = 0
+		} else if run.Outcome.IsFinished() {
+			in.ConsecutiveFailures += 1
+		}
+	}
+
+	sortRunsFromNewest(in.LastRuns)
+	if len(in.LastRuns) > CronJobRunsHistoryLimit {
+		in.LastRuns = in.LastRuns[0:CronJobRunsHistoryLimit]
+	}
+	return finished
+}
+
+// GetLatestFinishedRun returns the newest run that is not running anymore
+func (in *CronJobStatus) GetLatestFinishedRun() *CronJobRun {
+	var latest *CronJobRun
+	for i := range in.LastRuns {
+		run := &in.LastRuns[i]
+		if !run.Outcome.IsFinished() {
+			continue
+		}
+		if latest == nil || (run.StartTime != nil && (latest.StartTime == nil || latest.StartTime.Before(run.StartTime))) {
+			latest = run
+		}
+	}
+	return latest
+}
+
+func (in *CronJobStatus) findRun(jobName string) *CronJobRun {
+	for i := range in.LastRuns {
+		if in.LastRuns[i].JobName == jobName {
+			return &in.LastRuns[i]
+		}
+	}
+	return nil
+}
+
+func sortRunsFromNewest(runs []CronJobRun) {
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].StartTime == nil || runs[j].StartTime == nil {
+			return runs[j].StartTime == nil && runs[i].StartTime != nil
+		}
+		return runs[j].StartTime.Before(runs[i].StartTime)
+	})
+}
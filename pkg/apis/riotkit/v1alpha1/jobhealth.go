@@ -6,4 +6,19 @@ type JobHealthStatus struct {
 	Succeeded      bool   `json:"succeeded"`
 	Running        bool   `json:"running"`
 	Failed         bool   `json:"failed"`
+	// TimedOut is set together with Failed, when the object was running longer than it was allowed to
+	TimedOut bool `json:"timedOut,omitempty"`
+	// Failure tells why the object has failed, set together with Failed when the reason is known
+	Failure *FailureDetails `json:"failure,omitempty"`
+}
+
+// FailureDetails are describing a failed container of a failed object
+type FailureDetails struct {
+	PodName       string `json:"podName,omitempty"`
+	ContainerName string `json:"containerName,omitempty"`
+	ExitCode      int32  `json:"exitCode,omitempty"`
+	// Reason of the container termination (e.g. OOMKilled, Error), or of the whole object (e.g. DeadlineExceeded, BackoffLimitExceeded)
+	Reason string `json:"reason,omitempty"`
+	// LogTail are last lines of the container logs, truncated and with credentials redacted
+	LogTail string `json:"logTail,omitempty"`
 }
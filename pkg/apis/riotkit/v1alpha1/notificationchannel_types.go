@@ -0,0 +1,150 @@
+/*
+Copyright 2022 Riotkit.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// NotificationChannelRefSpec is a reference to a NotificationChannel in the same namespace
+type NotificationChannelRefSpec struct {
+	Name string `json:"name"`
+}
+
+// NotificationChannelSpec defines the desired state of NotificationChannel
+type NotificationChannelSpec struct {
+	Webhook WebhookSpec `json:"webhook"`
+
+	// OnlyOn limits notifications to given outcomes of a backup or restore. All outcomes are notified, when empty
+	// +kubebuilder:validation:items:Enum=Succeeded;Failed;TimedOut
+	OnlyOn []RunOutcome `json:"onlyOn,omitempty"`
+}
+
+// IsNotifiedOn tells if the outcome should be sent through the channel
+func (in *NotificationChannelSpec) IsNotifiedOn(outcome RunOutcome) bool {
+	if len(in.OnlyOn) == 0 {
+		return true
+	}
+	for _, allowed := range in.OnlyOn {
+		if allowed == outcome {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSpec is a generic HTTP endpoint receiving a JSON document with a POST request
+type WebhookSpec struct {
+	URL string `json:"url"`
+
+	// Headers are added to each request, e.g. an authorization token
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is a Go template rendering a JSON document. Fields of the notification are available as e.g. .Outcome, .Name, .Message,
+	// and `toJson` function is quoting the values. When empty, then the whole notification is sent as JSON
+	Body string `json:"body,omitempty"`
+
+	// HMAC signs the body with a key from a Secret, so the receiver could verify the sender
+	HMAC *HMACSpec `json:"hmac,omitempty"`
+
+	// MaxAttempts is a total number of delivery attempts, including the first one
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=3
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+
+	// Backoff is a delay before the second attempt. Every next attempt waits twice as long as the previous one
+	// +kubebuilder:default:="5s"
+	Backoff metav1.Duration `json:"backoff,omitempty"`
+
+	// Timeout of a single HTTP request
+	// +kubebuilder:default:="10s"
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// GetMaxAttempts is returning the number of delivery attempts, at least one
+func (in *WebhookSpec) GetMaxAttempts() int {
+	if in.MaxAttempts < 1 {
+		return 3
+	}
+	return in.MaxAttempts
+}
+
+// BackoffFor is returning a delay before given attempt, starting from 2 - the first attempt is not delayed
+func (in *WebhookSpec) BackoffFor(attempt int) time.Duration {
+	delay := in.Backoff.Duration
+	if delay == 0 {
+		delay = time.Second * 5
+	}
+	for i := 2; i < attempt; i++ {
+		delay *= 2
+	}
+	return delay
+}
+
+func (in *WebhookSpec) GetTimeout() time.Duration {
+	if in.Timeout.Duration == 0 {
+		return time.Second * 10
+	}
+	return in.Timeout.Duration
+}
+
+// HMACSpec represents .spec.webhook.hmac
+type HMACSpec struct {
+	SecretName string `json:"secretName"`
+	Key        string `json:"key"`
+
+	// Header carries the signature in format "sha256=<hex>"
+	// +kubebuilder:default:="X-Signature-256"
+	Header string `json:"header,omitempty"`
+}
+
+func (in *HMACSpec) GetHeader() string {
+	if in.Header == "" {
+		return "X-Signature-256"
+	}
+	return in.Header
+}
+
+// NotificationChannelStatus defines the observed state of NotificationChannel
+type NotificationChannelStatus struct {
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// NotificationChannel is the Schema for the notificationchannels API.
+// Receives results of backups and restores of ScheduledBackups and RequestedBackupActions referring to it
+type NotificationChannel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NotificationChannelSpec   `json:"spec,omitempty"`
+	Status NotificationChannelStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// NotificationChannelList contains a list of NotificationChannel
+type NotificationChannelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NotificationChannel `json:"items"`
+}
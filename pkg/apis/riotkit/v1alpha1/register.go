@@ -31,8 +31,12 @@ var (
 // Adds the list of known types to the Scheme.
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
+		&BackupProcedureTemplate{},
+		&BackupProcedureTemplateList{},
 		&ClusterBackupProcedureTemplate{},
 		&ClusterBackupProcedureTemplateList{},
+		&NotificationChannel{},
+		&NotificationChannelList{},
 		&RequestedBackupAction{},
 		&RequestedBackupActionList{},
 		&ScheduledBackup{},
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
 )
 
 type BackupRefSpec struct {
@@ -27,12 +28,142 @@ type BackupRefSpec struct {
 // RequestedBackupActionSpec defines the desired state of RequestedBackupAction
 type RequestedBackupActionSpec struct {
 	// +kubebuilder:validation:Enum=backup;restore
-	Action             string        `json:"action"`
+	Action string `json:"action"`
+
+	// TargetVersion selects a version to restore: "latest" (default), "latest-before: <RFC3339 time>", "previous:N",
+	// a version number e.g. "v3", or a version id. Resolved against versions stored in the Backup Repository before the restore starts
 	TargetVersion      string        `json:"targetVersion,omitempty"` // can be empty, when action = "backup"
 	ScheduledBackupRef BackupRefSpec `json:"scheduledBackupRef"`
 
+	// KindType is a kind of the object that performs the action: Kubernetes `Job` or `Pod`, Argo `Workflow`, Tekton `TaskRun` or `PipelineRun`
+	// +kubebuilder:validation:Enum=Job;Pod;Workflow;TaskRun;PipelineRun
 	// +kubebuilder:default:=Job
 	KindType string `json:"kindType"`
+
+	// RetryPolicy allows to perform the action once again in a fresh Job, when it has failed
+	RetryPolicy *RetryPolicySpec `json:"retryPolicy,omitempty"`
+
+	// Timeout is a maximum duration of a single attempt. When not set, then the timeout of ScheduledBackup is used
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// RestoreTarget allows to restore somewhere else than the backup was made, e.g. to clone production data into a staging namespace
+	RestoreTarget *RestoreTargetSpec `json:"restoreTarget,omitempty"`
+
+	// NotificationChannelRefs are notified, when the action is finished. When empty, then channels of the ScheduledBackup are notified
+	NotificationChannelRefs []NotificationChannelRefSpec `json:"notificationChannelRefs,omitempty"`
+}
+
+// AnnotationAllowRestoreFrom is a comma-separated list of namespaces, that are allowed to restore into the annotated namespace.
+// It is required, when admission webhooks are not enabled - those are checking permissions of the user in the target namespace
+const AnnotationAllowRestoreFrom = "riotkit.org/allow-restore-from"
+
+// RestoreTargetSpec represents .spec.restoreTarget
+type RestoreTargetSpec struct {
+	// Namespace, where the restore is performed. When empty, then the namespace of the RequestedBackupAction is used
+	Namespace string `json:"namespace,omitempty"`
+
+	// Vars are merged on top of .spec.vars of the ScheduledBackup, e.g. to restore into a different database host
+	Vars VarsSpec `json:"vars,omitempty"`
+}
+
+// GetNamespace is returning the namespace, where the restore is performed
+func (in *RestoreTargetSpec) GetNamespace(actionNamespace string) string {
+	if in.Namespace == "" {
+		return actionNamespace
+	}
+	return in.Namespace
+}
+
+// RetryPolicySpec decides if and when a failed action should be retried
+type RetryPolicySpec struct {
+	// MaxAttempts is a total number of attempts, including the first one
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts int `json:"maxAttempts"`
+
+	// Backoff is a delay before the second attempt. Every next attempt waits twice as long as the previous one
+	// +kubebuilder:default:="30s"
+	Backoff metav1.Duration `json:"backoff,omitempty"`
+
+	// OnlyOn lists phases of a finished attempt, that are qualifying for a retry
+	// +kubebuilder:default:={Failed,TimedOut}
+	OnlyOn []RequestedBackupActionPhase `json:"onlyOn,omitempty"`
+}
+
+const (
+	KindTypeJob         = "Job"
+	KindTypePod         = "Pod"
+	KindTypeWorkflow    = "Workflow"
+	KindTypeTaskRun     = "TaskRun"
+	KindTypePipelineRun = "PipelineRun"
+)
+
+// DefaultKindType is a kind of the object that performs the action, when .spec.kindType is not specified
+const DefaultKindType = KindTypeJob
+
+// DefaultRetryBackoff is a delay before the second attempt, when .spec.retryPolicy.backoff is not specified
+const DefaultRetryBackoff = time.Second * 30
+
+// Default is filling up empty optional fields, so all code paths are working on the same, effective values
+func (in *RequestedBackupActionSpec) Default() {
+	if in.KindType == "" {
+		in.KindType = DefaultKindType
+	}
+	if in.RetryPolicy != nil {
+		in.RetryPolicy.Default()
+	}
+}
+
+// Default is filling up empty optional fields of the retry policy
+func (in *RetryPolicySpec) Default() {
+	if in.Backoff.Duration == 0 {
+		in.Backoff = metav1.Duration{Duration: DefaultRetryBackoff}
+	}
+	if len(in.OnlyOn) == 0 {
+		in.OnlyOn = []RequestedBackupActionPhase{PhaseFailed, PhaseTimedOut}
+	}
+}
+
+// AllowsRetryOn tells if an attempt, that finished with given phase, can be retried
+func (in *RetryPolicySpec) AllowsRetryOn(phase RequestedBackupActionPhase) bool {
+	for _, allowed := range in.OnlyOn {
+		if allowed == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// BackoffFor is returning a delay before given attempt (counting from 1)
+func (in *RetryPolicySpec) BackoffFor(attempt int) time.Duration {
+	delay := in.Backoff.Duration
+	for i := 2; i < attempt; i++ {
+		delay *= 2
+	}
+	return delay
+}
+
+// RequestedBackupActionPhase is a step of the RequestedBackupAction lifecycle
+// +kubebuilder:validation:Enum=Pending;Rendering;Running;Succeeded;Failed;TimedOut
+type RequestedBackupActionPhase string
+
+const (
+	// PhasePending means that the action was not yet picked up, or its dependencies are not ready yet
+	PhasePending RequestedBackupActionPhase = "Pending"
+	// PhaseRendering means that the controller is templating and applying the Job
+	PhaseRendering RequestedBackupActionPhase = "Rendering"
+	// PhaseRunning means that the Job was created and is not finished yet
+	PhaseRunning RequestedBackupActionPhase = "Running"
+	// PhaseSucceeded is a terminal phase - the Job finished successfully
+	PhaseSucceeded RequestedBackupActionPhase = "Succeeded"
+	// PhaseFailed is a terminal phase - the Job failed
+	PhaseFailed RequestedBackupActionPhase = "Failed"
+	// PhaseTimedOut is a terminal phase - the Job was running longer than it was allowed to
+	PhaseTimedOut RequestedBackupActionPhase = "TimedOut"
+)
+
+// IsTerminal tells if the action has finished and its phase will not change anymore
+func (p RequestedBackupActionPhase) IsTerminal() bool {
+	return p == PhaseSucceeded || p == PhaseFailed || p == PhaseTimedOut
 }
 
 // RequestedBackupActionStatus defines the observed state of RequestedBackupAction
@@ -42,12 +173,42 @@ type RequestedBackupActionStatus struct {
 	OwnedReferences         ChildrenReferences `json:"ownedReferences,omitempty"`
 	ChildrenResourcesHealth []JobHealthStatus  `json:"childrenResourcesHealth,omitempty"`
 	Healthy                 bool               `json:"healthy,omitempty"`
+
+	Phase          RequestedBackupActionPhase `json:"phase,omitempty"`
+	StartTime      *metav1.Time               `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time               `json:"completionTime,omitempty"`
+	Attempts       int                        `json:"attempts,omitempty"`
+	// JobName is a name of the object (e.g. a Job) that performs the action
+	JobName string `json:"jobName,omitempty"`
+	// History contains every attempt of performing the action, the last one is the current attempt
+	History []ActionAttempt `json:"history,omitempty"`
+	// ResolvedVersion is a version selected by .spec.targetVersion, restored by every attempt
+	ResolvedVersion *BackupVersion `json:"resolvedVersion,omitempty"`
+}
+
+// ActionAttempt is a single try of performing the action in a separate Job
+type ActionAttempt struct {
+	Attempt        int                        `json:"attempt"`
+	TrackingId     string                     `json:"trackingId,omitempty"`
+	JobName        string                     `json:"jobName,omitempty"`
+	Phase          RequestedBackupActionPhase `json:"phase"`
+	StartTime      *metav1.Time               `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time               `json:"completionTime,omitempty"`
 }
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Action",type="string",JSONPath=".spec.action"
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.scheduledBackupRef.name"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Job",type="string",JSONPath=".status.jobName",priority=1
+// +kubebuilder:printcolumn:name="Version",type="integer",JSONPath=".status.resolvedVersion.number",priority=1
+// +kubebuilder:printcolumn:name="Attempts",type="integer",JSONPath=".status.attempts",priority=1
+// +kubebuilder:printcolumn:name="Started",type="date",JSONPath=".status.startTime"
+// +kubebuilder:printcolumn:name="Completed",type="date",JSONPath=".status.completionTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // RequestedBackupAction is the Schema for the requestedbackupactions API
 type RequestedBackupAction struct {
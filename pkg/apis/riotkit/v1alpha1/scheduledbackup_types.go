@@ -20,6 +20,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	json "encoding/json"
+	"fmt"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"time"
 )
@@ -27,16 +28,25 @@ import (
 // TemplateSpec represents .spec.templateRef section
 type TemplateSpec struct {
 	Name string `json:"name"`
+
+	// +kubebuilder:validation:Enum=internal;ClusterBackupProcedureTemplate;BackupProcedureTemplate
 	Kind string `json:"kind"`
 }
 
 // GPGKeySecretSpec represents .spec.gpgKeySecretRef section
 type GPGKeySecretSpec struct {
-	SecretName    string `json:"secretName"`
-	PublicKey     string `json:"publicKey"`
-	PrivateKey    string `json:"privateKey"`
-	PassphraseKey string `json:"passphraseKey"`
-	Email         string `json:"email"`
+	SecretName string `json:"secretName"`
+
+	// +kubebuilder:default:="key.pub"
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// +kubebuilder:default:="key"
+	PrivateKey string `json:"privateKey,omitempty"`
+
+	// +kubebuilder:default:="passphrase"
+	PassphraseKey string `json:"passphraseKey,omitempty"`
+
+	Email string `json:"email"`
 
 	CreateIfNotExists bool `json:"createIfNotExists"`
 }
@@ -66,6 +76,13 @@ func (in *GPGKeySecretSpec) GetEmailIndex() string {
 	return "e-mail.txt"
 }
 
+// Default is persisting the default Secret keys, so the effective values are visible to everyone
+func (in *GPGKeySecretSpec) Default() {
+	in.PrivateKey = in.GetPrivateKeyIndex()
+	in.PublicKey = in.GetPublicKeyIndex()
+	in.PassphraseKey = in.GetPassphraseIndex()
+}
+
 // TokenSecretSpec represents .spec.tokenSecretRef
 type TokenSecretSpec struct {
 	SecretName string `json:"secretName"`
@@ -91,16 +108,57 @@ type ScheduledBackupSpec struct {
 	Vars            VarsSpec         `json:"vars"`
 	CronJob         CronJobSpec      `json:"cronJob"`
 
+	// Timeout is a maximum duration of a single backup or restore run. Not finished run is killed
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// MaxBackupAge is the maximum age of the newest successful backup, before the ScheduledBackup is reported as overdue.
+	// When not set, then a backup is expected after each scheduled run, see .spec.cronJob.scheduleEvery
+	MaxBackupAge *metav1.Duration `json:"maxBackupAge,omitempty"`
+
 	// +kubebuilder:validation:Enum=backup;restore
 	Operation string `json:"operation"`
+
+	// Schedules are additional operations executed periodically, each one by its own CronJob.
+	// For example a daily backup configured in .spec.cronJob, and a weekly restore to verify that the backup is usable
+	Schedules []ScheduleSpec `json:"schedules,omitempty"`
+
+	// NotificationChannelRefs are notified about each finished backup or restore
+	NotificationChannelRefs []NotificationChannelRefSpec `json:"notificationChannelRefs,omitempty"`
+
+	// Retention decides which versions are kept in the Backup Repository collection, the rest is deleted after each successful backup.
+	// Backups performed by RequestedBackupActions are also triggering the retention
+	Retention *RetentionSpec `json:"retention,omitempty"`
+}
+
+// GetSchedules is returning all effective schedules - the one from .spec.cronJob (when enabled) and all from .spec.schedules
+func (in *ScheduledBackupSpec) GetSchedules() []ScheduleSpec {
+	var schedules []ScheduleSpec
+	if in.CronJob.Enabled {
+		schedules = append(schedules, ScheduleSpec{Operation: in.Operation, ScheduleEvery: in.CronJob.ScheduleEvery})
+	}
+	return append(schedules, in.Schedules...)
 }
 
+// Default is filling up empty optional fields, so all code paths are working on the same, effective values
+func (in *ScheduledBackupSpec) Default() {
+	in.GPGKeySecretRef.Default()
+	if in.CronJob.ScheduleEvery == "" {
+		in.CronJob.ScheduleEvery = DefaultScheduleEvery
+	}
+}
+
+// CalculateHash is calculating a checksum of the effective spec - an object with and without defaults filled up has the same checksum
 func (in *ScheduledBackupSpec) CalculateHash() string {
-	doc, _ := json.Marshal(in)
+	effective := in.DeepCopy()
+	effective.Default()
+	doc, _ := json.Marshal(effective)
 	sum := sha256.Sum256(doc)
 	return hex.EncodeToString(sum[0:])
 }
 
+// DefaultScheduleEvery is a schedule used, when .spec.cronJob.scheduleEvery is not specified
+const DefaultScheduleEvery = "00 02 * * *"
+
 type CronJobSpec struct {
 	Enabled bool `json:"enabled"`
 
@@ -108,6 +166,69 @@ type CronJobSpec struct {
 	ScheduleEvery string `json:"scheduleEvery,omitempty"`
 }
 
+// ScheduleSpec represents a single entry of .spec.schedules
+type ScheduleSpec struct {
+	// Name is distinguishing CronJobs of the same ScheduledBackup. Empty only for the schedule defined in .spec.cronJob
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Enum=backup;restore
+	Operation string `json:"operation"`
+
+	ScheduleEvery string `json:"scheduleEvery"`
+}
+
+// GetCronJobName is returning a name of the CronJob created for this schedule, e.g. "app1-backup" for .spec.cronJob
+// and "app1-restore-weekly" for a schedule named "weekly"
+func (in *ScheduleSpec) GetCronJobName(scheduledBackupName string) string {
+	name := scheduledBackupName + "-" + in.Operation
+	if in.Name != "" {
+		name += "-" + in.Name
+	}
+	return name
+}
+
+// MaxCronJobNameLength is a limit set by Kubernetes - the CronJob controller is appending 11 characters to names of spawned Jobs
+const MaxCronJobNameLength = 52
+
+// RetentionSpec represents .spec.retention. A version is kept, when any of keep* rules selects it - or when no keep* rule is set.
+// The newest version is always kept, even when it is older than .maxAge
+type RetentionSpec struct {
+	// KeepLast keeps N newest versions
+	// +kubebuilder:validation:Minimum=0
+	KeepLast int `json:"keepLast,omitempty"`
+
+	// KeepDaily keeps the newest version of each of N last days, that have any version
+	// +kubebuilder:validation:Minimum=0
+	KeepDaily int `json:"keepDaily,omitempty"`
+
+	// KeepWeekly keeps the newest version of each of N last ISO weeks, that have any version
+	// +kubebuilder:validation:Minimum=0
+	KeepWeekly int `json:"keepWeekly,omitempty"`
+
+	// KeepMonthly keeps the newest version of each of N last months, that have any version
+	// +kubebuilder:validation:Minimum=0
+	KeepMonthly int `json:"keepMonthly,omitempty"`
+
+	// MaxAge deletes versions older than given duration, also those selected by keep* rules
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// DryRun only reports versions, that would be deleted
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// HasKeepRules tells if any of keep* rules is selecting versions. Without those, all versions not older than .maxAge are kept
+func (in *RetentionSpec) HasKeepRules() bool {
+	return in.KeepLast > 0 || in.KeepDaily > 0 || in.KeepWeekly > 0 || in.KeepMonthly > 0
+}
+
+// ScheduleStatus is a history of runs of a single entry of .spec.schedules
+type ScheduleStatus struct {
+	Name          string `json:"name"`
+	Operation     string `json:"operation"`
+	CronJobStatus `json:",inline"`
+}
+
 // ScheduledBackupStatus defines the observed state of ScheduledBackup
 type ScheduledBackupStatus struct {
 	LastAppliedSpecHash     string             `json:"lastAppliedSpecHash,omitempty"`
@@ -115,13 +236,135 @@ type ScheduledBackupStatus struct {
 	OwnedReferences         ChildrenReferences `json:"ownedReferences,omitempty"`
 	ChildrenResourcesHealth []JobHealthStatus  `json:"childrenResourcesHealth,omitempty"`
 	Healthy                 bool               `json:"healthy,omitempty"`
+	CronJob                 *CronJobStatus     `json:"cronJob,omitempty"`
+	Schedules               []ScheduleStatus   `json:"schedules,omitempty"`
+
+	// Repository lists the newest versions stored in the Backup Repository collection, e.g. to choose a .spec.targetVersion of a restore
+	Repository *RepositoryStatus `json:"repository,omitempty"`
+
+	// Retention is a result of the last application of .spec.retention
+	Retention *RetentionStatus `json:"retention,omitempty"`
+}
+
+// RepositoryStatus is a view on the Backup Repository collection of .spec.collectionId
+type RepositoryStatus struct {
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// TotalVersions is a number of all versions in the collection, only the newest ones are listed in .versions
+	TotalVersions int `json:"totalVersions"`
+
+	// Versions are starting from the newest one
+	Versions []BackupVersion `json:"versions,omitempty"`
+}
+
+// IsOutdated tells if the versions should be listed again
+func (in *RepositoryStatus) IsOutdated(now time.Time, interval time.Duration) bool {
+	return in == nil || in.LastSyncTime == nil || !now.Before(in.LastSyncTime.Add(interval))
+}
+
+// BackupVersion is a single uploaded backup
+type BackupVersion struct {
+	Id        string      `json:"id"`
+	Number    int         `json:"number"`
+	Filename  string      `json:"filename,omitempty"`
+	Size      int64       `json:"size"`
+	CreatedAt metav1.Time `json:"createdAt"`
+}
+
+// GetName is a version reference understood by Backup Maker's `restore --version`, e.g. "v3"
+func (in *BackupVersion) GetName() string {
+	return fmt.Sprintf("v%d", in.Number)
+}
+
+// RetentionStatus is a report of versions kept and deleted by .spec.retention
+type RetentionStatus struct {
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// DryRun means, that .removed versions were only reported and are still stored in the collection
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Kept are names of versions left in the collection, starting from the newest one
+	Kept []string `json:"kept,omitempty"`
+
+	// Removed are names of versions deleted from the collection
+	Removed []string `json:"removed,omitempty"`
+
+	// JobName is a Job applying the retention, when Backup Repository is not accessible by the controller. Its logs are the report
+	JobName string `json:"jobName,omitempty"`
+}
+
+// GetLastAppliedTime is returning a zero time, when the retention was never applied
+func (in *RetentionStatus) GetLastAppliedTime() time.Time {
+	if in == nil || in.LastAppliedTime == nil {
+		return time.Time{}
+	}
+	return in.LastAppliedTime.Time
+}
+
+// GetCronJobStatus is returning the history of runs of given schedule, nil when nothing was recorded yet
+func (in *ScheduledBackupStatus) GetCronJobStatus(schedule ScheduleSpec) *CronJobStatus {
+	if schedule.Name == "" {
+		return in.CronJob
+	}
+	for i := range in.Schedules {
+		if in.Schedules[i].Name == schedule.Name {
+			return &in.Schedules[i].CronJobStatus
+		}
+	}
+	return nil
+}
+
+// RecordCronJobRuns is merging runs observed on the cluster into the history of given schedule.
+// Returns runs that finished since the previous observation
+func (in *ScheduledBackupStatus) RecordCronJobRuns(schedule ScheduleSpec, observed CronJobStatus) []CronJobRun {
+	if schedule.Name == "" {
+		if in.CronJob == nil {
+			in.CronJob = &CronJobStatus{}
+		}
+		return in.CronJob.Record(observed)
+	}
+	for i := range in.Schedules {
+		if in.Schedules[i].Name != schedule.Name {
+			continue
+		}
+		// runs of a different operation are not a history of this schedule anymore
+		if in.Schedules[i].Operation != schedule.Operation {
+			in.Schedules[i] = ScheduleStatus{Name: schedule.Name, Operation: schedule.Operation}
+		}
+		return in.Schedules[i].Record(observed)
+	}
+	status := ScheduleStatus{Name: schedule.Name, Operation: schedule.Operation}
+	finished := status.Record(observed)
+	in.Schedules = append(in.Schedules, status)
+	return finished
+}
+
+// ForgetRemovedSchedules is removing the history of schedules that are no longer present in the spec
+func (in *ScheduledBackupStatus) ForgetRemovedSchedules(schedules []ScheduleSpec) {
+	kept := make([]ScheduleStatus, 0, len(in.Schedules))
+	for _, status := range in.Schedules {
+		for _, schedule := range schedules {
+			if schedule.Name != "" && schedule.Name == status.Name {
+				kept = append(kept, status)
+				break
+			}
+		}
+	}
+	in.Schedules = kept
+	if len(in.Schedules) == 0 {
+		in.Schedules = nil
+	}
 }
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.cronjob.scheduleEvery",description="Cron expression"
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.cronJob.scheduleEvery",description="Cron expression"
+// +kubebuilder:printcolumn:name="Healthy",type="boolean",JSONPath=".status.healthy"
+// +kubebuilder:printcolumn:name="Last Success",type="date",JSONPath=".status.cronJob.lastSuccessfulTime"
+// +kubebuilder:printcolumn:name="Latest Version",type="integer",JSONPath=".status.repository.versions[0].number",description="Newest version in the Backup Repository",priority=1
+// +kubebuilder:printcolumn:name="Failures",type="integer",JSONPath=".status.cronJob.consecutiveFailures",description="Consecutive failed runs",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ScheduledBackup is the Schema for the scheduledbackups API
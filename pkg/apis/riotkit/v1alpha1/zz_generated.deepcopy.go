@@ -26,6 +26,118 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionAttempt) DeepCopyInto(out *ActionAttempt) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionAttempt.
+func (in *ActionAttempt) DeepCopy() *ActionAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupProcedureTemplate) DeepCopyInto(out *BackupProcedureTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupProcedureTemplate.
+func (in *BackupProcedureTemplate) DeepCopy() *BackupProcedureTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupProcedureTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupProcedureTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupProcedureTemplateList) DeepCopyInto(out *BackupProcedureTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BackupProcedureTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupProcedureTemplateList.
+func (in *BackupProcedureTemplateList) DeepCopy() *BackupProcedureTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupProcedureTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupProcedureTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupProcedureTemplateSpec) DeepCopyInto(out *BackupProcedureTemplateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupProcedureTemplateSpec.
+func (in *BackupProcedureTemplateSpec) DeepCopy() *BackupProcedureTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupProcedureTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupProcedureTemplateStatus) DeepCopyInto(out *BackupProcedureTemplateStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupProcedureTemplateStatus.
+func (in *BackupProcedureTemplateStatus) DeepCopy() *BackupProcedureTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupProcedureTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupRefSpec) DeepCopyInto(out *BackupRefSpec) {
 	*out = *in
@@ -41,6 +153,22 @@ func (in *BackupRefSpec) DeepCopy() *BackupRefSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVersion) DeepCopyInto(out *BackupVersion) {
+	*out = *in
+	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVersion.
+func (in *BackupVersion) DeepCopy() *BackupVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChildReference) DeepCopyInto(out *ChildReference) {
 	*out = *in
@@ -164,6 +292,29 @@ func (in *ClusterBackupProcedureTemplateStatus) DeepCopy() *ClusterBackupProcedu
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronJobRun) DeepCopyInto(out *CronJobRun) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobRun.
+func (in *CronJobRun) DeepCopy() *CronJobRun {
+	if in == nil {
+		return nil
+	}
+	out := new(CronJobRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CronJobSpec) DeepCopyInto(out *CronJobSpec) {
 	*out = *in
@@ -179,6 +330,51 @@ func (in *CronJobSpec) DeepCopy() *CronJobSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronJobStatus) DeepCopyInto(out *CronJobStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRuns != nil {
+		in, out := &in.LastRuns, &out.LastRuns
+		*out = make([]CronJobRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobStatus.
+func (in *CronJobStatus) DeepCopy() *CronJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CronJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureDetails) DeepCopyInto(out *FailureDetails) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureDetails.
+func (in *FailureDetails) DeepCopy() *FailureDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GPGKeySecretSpec) DeepCopyInto(out *GPGKeySecretSpec) {
 	*out = *in
@@ -194,10 +390,30 @@ func (in *GPGKeySecretSpec) DeepCopy() *GPGKeySecretSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HMACSpec) DeepCopyInto(out *HMACSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HMACSpec.
+func (in *HMACSpec) DeepCopy() *HMACSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HMACSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobHealthStatus) DeepCopyInto(out *JobHealthStatus) {
 	*out = *in
 	out.ChildReference = in.ChildReference
+	if in.Failure != nil {
+		in, out := &in.Failure, &out.Failure
+		*out = new(FailureDetails)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobHealthStatus.
@@ -210,12 +426,148 @@ func (in *JobHealthStatus) DeepCopy() *JobHealthStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationChannel) DeepCopyInto(out *NotificationChannel) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationChannel.
+func (in *NotificationChannel) DeepCopy() *NotificationChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationChannel) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationChannelList) DeepCopyInto(out *NotificationChannelList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NotificationChannel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationChannelList.
+func (in *NotificationChannelList) DeepCopy() *NotificationChannelList {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationChannelList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationChannelList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationChannelRefSpec) DeepCopyInto(out *NotificationChannelRefSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationChannelRefSpec.
+func (in *NotificationChannelRefSpec) DeepCopy() *NotificationChannelRefSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationChannelRefSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationChannelSpec) DeepCopyInto(out *NotificationChannelSpec) {
+	*out = *in
+	in.Webhook.DeepCopyInto(&out.Webhook)
+	if in.OnlyOn != nil {
+		in, out := &in.OnlyOn, &out.OnlyOn
+		*out = make([]RunOutcome, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationChannelSpec.
+func (in *NotificationChannelSpec) DeepCopy() *NotificationChannelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationChannelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationChannelStatus) DeepCopyInto(out *NotificationChannelStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationChannelStatus.
+func (in *NotificationChannelStatus) DeepCopy() *NotificationChannelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationChannelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryStatus) DeepCopyInto(out *RepositoryStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]BackupVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryStatus.
+func (in *RepositoryStatus) DeepCopy() *RepositoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RequestedBackupAction) DeepCopyInto(out *RequestedBackupAction) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -273,6 +625,26 @@ func (in *RequestedBackupActionList) DeepCopyObject() runtime.Object {
 func (in *RequestedBackupActionSpec) DeepCopyInto(out *RequestedBackupActionSpec) {
 	*out = *in
 	out.ScheduledBackupRef = in.ScheduledBackupRef
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RestoreTarget != nil {
+		in, out := &in.RestoreTarget, &out.RestoreTarget
+		*out = new(RestoreTargetSpec)
+		**out = **in
+	}
+	if in.NotificationChannelRefs != nil {
+		in, out := &in.NotificationChannelRefs, &out.NotificationChannelRefs
+		*out = make([]NotificationChannelRefSpec, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestedBackupActionSpec.
@@ -303,7 +675,29 @@ func (in *RequestedBackupActionStatus) DeepCopyInto(out *RequestedBackupActionSt
 	if in.ChildrenResourcesHealth != nil {
 		in, out := &in.ChildrenResourcesHealth, &out.ChildrenResourcesHealth
 		*out = make([]JobHealthStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]ActionAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResolvedVersion != nil {
+		in, out := &in.ResolvedVersion, &out.ResolvedVersion
+		*out = new(BackupVersion)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -317,6 +711,122 @@ func (in *RequestedBackupActionStatus) DeepCopy() *RequestedBackupActionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTargetSpec) DeepCopyInto(out *RestoreTargetSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTargetSpec.
+func (in *RestoreTargetSpec) DeepCopy() *RestoreTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionSpec) DeepCopyInto(out *RetentionSpec) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionSpec.
+func (in *RetentionSpec) DeepCopy() *RetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionStatus) DeepCopyInto(out *RetentionStatus) {
+	*out = *in
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Kept != nil {
+		in, out := &in.Kept, &out.Kept
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Removed != nil {
+		in, out := &in.Removed, &out.Removed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionStatus.
+func (in *RetentionStatus) DeepCopy() *RetentionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicySpec) DeepCopyInto(out *RetryPolicySpec) {
+	*out = *in
+	out.Backoff = in.Backoff
+	if in.OnlyOn != nil {
+		in, out := &in.OnlyOn, &out.OnlyOn
+		*out = make([]RequestedBackupActionPhase, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicySpec.
+func (in *RetryPolicySpec) DeepCopy() *RetryPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
+func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleStatus) DeepCopyInto(out *ScheduleStatus) {
+	*out = *in
+	in.CronJobStatus.DeepCopyInto(&out.CronJobStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleStatus.
+func (in *ScheduleStatus) DeepCopy() *ScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScheduledBackup) DeepCopyInto(out *ScheduledBackup) {
 	*out = *in
@@ -384,6 +894,31 @@ func (in *ScheduledBackupSpec) DeepCopyInto(out *ScheduledBackupSpec) {
 	out.TokenSecretRef = in.TokenSecretRef
 	in.VarsSecretRef.DeepCopyInto(&out.VarsSecretRef)
 	out.CronJob = in.CronJob
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBackupAge != nil {
+		in, out := &in.MaxBackupAge, &out.MaxBackupAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]ScheduleSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotificationChannelRefs != nil {
+		in, out := &in.NotificationChannelRefs, &out.NotificationChannelRefs
+		*out = make([]NotificationChannelRefSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(RetentionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledBackupSpec.
@@ -414,7 +949,31 @@ func (in *ScheduledBackupStatus) DeepCopyInto(out *ScheduledBackupStatus) {
 	if in.ChildrenResourcesHealth != nil {
 		in, out := &in.ChildrenResourcesHealth, &out.ChildrenResourcesHealth
 		*out = make([]JobHealthStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CronJob != nil {
+		in, out := &in.CronJob, &out.CronJob
+		*out = new(CronJobStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]ScheduleStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Repository != nil {
+		in, out := &in.Repository, &out.Repository
+		*out = new(RepositoryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(RetentionStatus)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -477,3 +1036,32 @@ func (in *VarsSecretSpec) DeepCopy() *VarsSecretSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSpec) DeepCopyInto(out *WebhookSpec) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HMAC != nil {
+		in, out := &in.HMAC, &out.HMAC
+		*out = new(HMACSpec)
+		**out = **in
+	}
+	out.Backoff = in.Backoff
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSpec.
+func (in *WebhookSpec) DeepCopy() *WebhookSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -2,31 +2,37 @@ package bmg
 
 import (
 	"context"
+	"fmt"
 	"github.com/pkg/errors"
 	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
 	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/riotkit-org/backup-maker-controller/pkg/metrics"
 	"github.com/sirupsen/logrus"
+	"io/fs"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 )
 
 // ApplyObjects is applying objects to the cluster, while adding necessary metadata
-func ApplyObjects(ctx context.Context, logger *logrus.Entry, recorder record.EventRecorder, restCfg *rest.Config, dynClient dynamic.Interface, backup domain.Renderable) error {
-	rendered, renderErr := RenderKubernetesResourcesFor(logger, backup)
+func ApplyObjects(ctx context.Context, logger *logrus.Entry, recorder record.EventRecorder, mapper meta.ResettableRESTMapper, dynClient dynamic.Interface, resources fs.FS, backup domain.Renderable, conflictPolicy ConflictPolicy) error {
+	labels := metrics.Labels(backup.GetScheduledBackup(), string(backup.GetOperation()))
+	rendered, renderErr := RenderKubernetesResourcesFor(logger, resources, backup)
 	if renderErr != nil {
 		logger.Errorln(renderErr)
+		metrics.RenderErrors.With(labels).Inc()
 		return errors.Wrap(renderErr, "cannot apply rendered objects to the cluster")
 	}
 
 	// add owner references and namespaces to all objects that this controller creates
 	for _, doc := range rendered {
+		addNamespace(&doc, backup.GetTargetNamespace())
 		addOwnerReferences(logger, &doc, backup)
-		addNamespace(&doc, backup.GetScheduledBackup().Namespace)
 	}
 
 	if len(rendered) == 0 {
+		metrics.RenderErrors.With(labels).Inc()
 		return errors.Errorf("no objects rendered, no objects to apply. Please check your template - it did not render any of those objects: %v", backup.AcceptedResourceTypes())
 	}
 	for _, doc := range rendered {
@@ -36,7 +42,8 @@ func ApplyObjects(ctx context.Context, logger *logrus.Entry, recorder record.Eve
 		// mark a resource with a unique identifier in the label
 		v1alpha1.AppendJobIdTo(&doc)
 
-		if err := CreateOrUpdate(ctx, recorder, dynClient, restCfg, &doc, backup.GetScheduledBackup()); err != nil {
+		if err := CreateOrUpdate(ctx, recorder, dynClient, mapper, &doc, backup.GetScheduledBackup(), conflictPolicy); err != nil {
+			metrics.ApplyErrors.With(labels).Inc()
 			return errors.Wrap(err, "cannot apply manifest to the cluster")
 		}
 		addChildReferences(&doc, backup)
@@ -55,6 +62,19 @@ func addOwnerReferences(logger *logrus.Entry, doc *unstructured.Unstructured, ba
 	}
 
 	owner := backup.GetObjectForOwnerReference()
+
+	// Kubernetes does not allow owners from other namespaces - the garbage collector would delete such object at once.
+	// The owner is cleaning up those objects by itself, see .status.ownedReferences
+	if doc.GetNamespace() != owner.GetObjectMeta().Namespace {
+		annotations := doc.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[v1alpha1.AnnotationOwner] = fmt.Sprintf("%s/%s/%s", owner.GetTypeMeta().Kind, owner.GetObjectMeta().Namespace, owner.GetObjectMeta().Name)
+		doc.SetAnnotations(annotations)
+		logger.Debugf("Not attaching ownerReferences to %s/%s, annotating with %s", doc.GetNamespace(), doc.GetName(), annotations[v1alpha1.AnnotationOwner])
+		return
+	}
 	metadata := doc.Object["metadata"].(map[string]interface{})
 	metadata["ownerReferences"] = []map[string]interface{}{
 		{
@@ -62,6 +82,8 @@ func addOwnerReferences(logger *logrus.Entry, doc *unstructured.Unstructured, ba
 			"kind":       owner.GetTypeMeta().Kind,
 			"name":       owner.GetObjectMeta().Name,
 			"uid":        owner.GetObjectMeta().UID,
+			// observers are watching children using `Owns()`, which is matching only the controller reference
+			"controller": true,
 		},
 	}
 	logger.Debugf("Attaching ownerReferences = %v", metadata["ownerReferences"])
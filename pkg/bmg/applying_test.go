@@ -0,0 +1,44 @@
+package bmg
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+)
+
+// TestAddOwnerReferences_OtherNamespace is checking that objects placed outside the owner's namespace are only annotated,
+// as Kubernetes garbage collector would delete objects with a cross-namespace owner
+func TestAddOwnerReferences_OtherNamespace(t *testing.T) {
+	action := domain.NewRequestedBackupActionAggregate(&v1alpha1.RequestedBackupAction{
+		ObjectMeta: v1.ObjectMeta{Name: "clone-to-staging", Namespace: "production", UID: "1234"},
+		Spec: v1alpha1.RequestedBackupActionSpec{
+			Action:        "restore",
+			RestoreTarget: &v1alpha1.RestoreTargetSpec{Namespace: "staging"},
+		},
+	}, &domain.ScheduledBackupAggregate{
+		ScheduledBackup:    &v1alpha1.ScheduledBackup{ObjectMeta: v1.ObjectMeta{Name: "app1", Namespace: "production"}},
+		AdditionalVarsList: domain.AdditionalVarsList{},
+	})
+	logger := logrus.WithContext(context.TODO())
+
+	// same namespace
+	doc := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	addNamespace(doc, "production")
+	addOwnerReferences(logger, doc, action)
+	ownerReferences := doc.Object["metadata"].(map[string]interface{})["ownerReferences"].([]map[string]interface{})
+	assert.Len(t, ownerReferences, 1)
+	assert.Equal(t, "clone-to-staging", ownerReferences[0]["name"])
+	assert.Empty(t, doc.GetAnnotations())
+
+	// other namespace
+	doc = &unstructured.Unstructured{Object: map[string]interface{}{}}
+	addNamespace(doc, action.GetTargetNamespace())
+	addOwnerReferences(logger, doc, action)
+	assert.NotContains(t, doc.Object["metadata"], "ownerReferences")
+	assert.Equal(t, "RequestedBackupAction/production/clone-to-staging", doc.GetAnnotations()[v1alpha1.AnnotationOwner])
+}
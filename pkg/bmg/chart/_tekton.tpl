@@ -0,0 +1,24 @@
+{{- /* Tekton has no init containers - the steps are run one after another and share the volumes */ -}}
+{{- define "tektonTaskSpec" -}}
+{{- $pod := include "podSpecTemplate" . | fromYaml -}}
+volumes:
+    {{- toYaml $pod.volumes | nindent 4 }}
+steps:
+    {{- toYaml (concat $pod.initContainers $pod.containers) | nindent 4 }}
+{{- end }}
+
+{{- define "tektonPodTemplate" -}}
+{{- $pod := include "podSpecTemplate" . | fromYaml -}}
+{{- with $pod.nodeSelector }}
+nodeSelector:
+    {{- toYaml . | nindent 4 }}
+{{- end }}
+{{- with $pod.affinity }}
+affinity:
+    {{- toYaml . | nindent 4 }}
+{{- end }}
+{{- with $pod.tolerations }}
+tolerations:
+    {{- toYaml . | nindent 4 }}
+{{- end }}
+{{- end }}
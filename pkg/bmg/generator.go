@@ -0,0 +1,230 @@
+package bmg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/riotkit-org/br-backup-maker/generate"
+	"github.com/riotkit-org/br-backup-maker/version"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/engine"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//
+// In-memory equivalent of Backup Maker Generator's `SnippetGenerationCommand` for Kubernetes output.
+// The generator itself is looking for files relative to the current working directory and $HOME,
+// which is not safe, when multiple reconciliations are rendering at the same time
+//
+
+// gpgKeyMountPath is where the GPG key is mounted inside the backup/restore Pod
+const gpgKeyMountPath = "/mnt/secrets/gpg-key"
+
+// scriptFuncs are the same functions that Backup Maker Generator offers in the backup/restore scripts
+var scriptFuncs = template.FuncMap{
+	"toJson": func(s any) string {
+		m, err := json.Marshal(s)
+		if err != nil {
+			logrus.Errorf("toJson error: '%s'", err.Error())
+		}
+		return string(m)
+	},
+	"replace": func(s1 string, s2 string) string {
+		return strings.Replace(s2, s1, "", -1)
+	},
+	"findreplace": func(s1 string, s2 string, s3 string) string {
+		return strings.Replace(s3, s1, s2, -1)
+	},
+	"trim": func(s string) string {
+		return strings.TrimSpace(s)
+	},
+	"hasKey": func(a map[string]interface{}, s string) bool {
+		_, hasKey := a[s]
+		return hasKey
+	},
+	"default": func(arg interface{}, value interface{}) interface{} {
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			if v.Len() == 0 {
+				return arg
+			}
+		case reflect.Bool:
+			if !v.Bool() {
+				return arg
+			}
+		}
+		return value
+	},
+}
+
+// renderScript is rendering a backup/restore procedure script, which is a Go template
+func renderScript(name string, script string, cfg *generate.ComputedConfiguration) (string, error) {
+	parsed, parseErr := template.New(name).Funcs(scriptFuncs).Parse(script)
+	if parseErr != nil {
+		return "", errors.Wrapf(parseErr, "cannot parse template '%s'", name)
+	}
+	buffer := bytes.NewBufferString("")
+	if err := parsed.Execute(buffer, cfg.GetConfig().ToMap()); err != nil {
+		return "", errors.Wrapf(err, "cannot render template '%s', execution failed", name)
+	}
+	return buffer.String(), nil
+}
+
+// chartParams are values passed to the Helm chart, that are not coming from .HelmValues
+type chartParams struct {
+	Script    string
+	GPGKey    string
+	Schedule  string
+	JobName   string
+	Image     string
+	Namespace string
+	Operation domain.Operation
+	// KindType is a kind of the runnable object. When empty, then a CronJob is rendered for backup and a Job for restore
+	KindType string
+	// Env is added to the user's .HelmValues.extraEnv of the container performing the operation
+	Env map[string]string
+}
+
+// appendExtraEnv is adding variables to a copy of .HelmValues.extraEnv, variables defined by the user are replaced
+func appendExtraEnv(userEnv interface{}, env map[string]string) []interface{} {
+	var merged []interface{}
+	if list, ok := userEnv.([]interface{}); ok {
+		for _, item := range list {
+			if entry, isMap := item.(map[string]interface{}); isMap {
+				if _, replaced := env[fmt.Sprint(entry["name"])]; replaced {
+					continue
+				}
+			}
+			merged = append(merged, item)
+		}
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		merged = append(merged, map[string]interface{}{"name": name, "value": env[name]})
+	}
+	return merged
+}
+
+// sealedSecret is a GPG key stored in Git as a `kind: SealedSecret`, which is unsealed into a Secret by the SealedSecrets controller
+type sealedSecret struct {
+	Metadata struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Spec struct {
+		EncryptedData struct {
+			GpgKey string `yaml:"gpg-key"`
+		} `yaml:"encryptedData"`
+	} `yaml:"spec"`
+}
+
+// detectSealedSecret is checking if the GPG key is a SealedSecret, same as Backup Maker Generator does.
+// Returns name of the Secret, that the SealedSecret will be unsealed into
+func detectSealedSecret(gpgKey string, namespace string, jobName string) (string, bool, error) {
+	if !strings.Contains(gpgKey, "kind: SealedSecret") {
+		return "", false, nil
+	}
+	var sealed sealedSecret
+	if err := yaml.Unmarshal([]byte(gpgKey), &sealed); err != nil {
+		return "", true, errors.Wrap(err, "SealedSecret is invalid")
+	}
+	if sealed.Spec.EncryptedData.GpgKey == "" {
+		return "", true, errors.New("SealedSecret is invalid: missing .spec.encryptedData.gpg-key")
+	}
+	if sealed.Metadata.Namespace != namespace {
+		return "", true, errors.Errorf("SealedSecret is invalid: it is in different Namespace (%s), expected to be in '%s'", sealed.Metadata.Namespace, namespace)
+	}
+	if sealed.Metadata.Name == jobName {
+		return "", true, errors.Errorf("SealedSecret is invalid: .metadata.name cannot be same as '%s'", jobName)
+	}
+	return sealed.Metadata.Name, true, nil
+}
+
+// renderChart is rendering the Helm chart into a multi-document YAML. Documents are sorted by template name
+func renderChart(templates []*chart.File, params chartParams, cfg *generate.ComputedConfiguration) (string, error) {
+	sealedSecretName, isSealedSecret, sealedErr := detectSealedSecret(params.GPGKey, params.Namespace, params.JobName)
+	if sealedErr != nil {
+		return "", sealedErr
+	}
+	kindType := params.KindType
+	if kindType == "" {
+		kindType = "CronJob"
+		if params.Operation == domain.Restore {
+			kindType = "Job"
+		}
+	}
+
+	values := map[string]interface{}{
+		"name":              params.JobName,
+		"Name":              params.JobName,
+		"scriptContent":     params.Script,
+		"gpgKeyContent":     params.GPGKey,
+		"schedule":          params.Schedule,
+		"image":             params.Image,
+		"scriptName":        params.JobName,
+		"sealedSecretName":  sealedSecretName,
+		"kindType":          kindType,
+		"operationType":     string(params.Operation),
+		"isGPGSealedSecret": isSealedSecret,
+		"backupMakerImage":  version.GetSelfContainerImage(),
+		"serviceAccount": map[string]interface{}{
+			"name":   "default",
+			"create": false,
+		},
+		"cronjob": make(map[string]interface{}),
+	}
+	// NOTICE: Unlike the CLI, the .HelmValues.env is not evaluated in a local shell - the controller is not a place to run user's commands
+	for key, val := range cfg.GetConfig().HelmValues {
+		values[key] = val
+	}
+	if len(params.Env) > 0 {
+		values["extraEnv"] = appendExtraEnv(values["extraEnv"], params.Env)
+	}
+	valuesVolume := map[string]interface{}{
+		"Values": values,
+		"Release": map[string]interface{}{
+			"Namespace": params.Namespace,
+		},
+	}
+
+	c := chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:        "rkc",
+			Home:        "https://github.com/riotkit-org/br-backup-maker",
+			Version:     "1.0",
+			Description: "Backup or Restore job",
+			AppVersion:  "1.0",
+			Type:        "application",
+		},
+		Templates: templates,
+		Values:    valuesVolume,
+	}
+	files, err := engine.Engine{Strict: false}.Render(&c, valuesVolume)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot render a chart")
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	contents := make([]string, 0, len(files))
+	for _, name := range names {
+		contents = append(contents, files[name])
+	}
+	return strings.Join(contents, "\n"), nil
+}
@@ -0,0 +1,87 @@
+package bmg
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
+)
+
+// PruneOrphanedObjects is deleting objects that were applied previously, but are no longer rendered.
+// For example a CronJob, when `.spec.cronJob.enabled` was switched to false, or a ConfigMap that the template stopped producing
+func PruneOrphanedObjects(ctx context.Context, logger *logrus.Entry, recorder record.EventRecorder, mapper meta.ResettableRESTMapper, dynClient dynamic.Interface, backup domain.Renderable, previous v1alpha1.ChildrenReferences) error {
+	namespace := backup.GetScheduledBackup().Namespace
+
+	for _, orphan := range findOrphans(previous, backup.GetReferencesOfOwnedObjects()) {
+		logger.Infof("Pruning orphaned %s, kind: %s, trackingId: %s", orphan.APIVersion, orphan.Kind, orphan.TrackingId)
+
+		deleted, err := deleteOrphan(ctx, mapper, dynClient, orphan, orphan.GetNamespace(namespace))
+		if err != nil {
+			return errors.Wrapf(err, "cannot prune orphaned %s labelled with %s=%s", orphan.Kind, v1alpha1.LabelTrackingId, orphan.TrackingId)
+		}
+		for _, name := range deleted {
+			recorder.Event(backup.GetScheduledBackup(), "Normal", "Deleted", fmt.Sprintf("Deleting orphaned %s/%s, named %s/%s", orphan.APIVersion, orphan.Kind, orphan.GetNamespace(namespace), name))
+		}
+	}
+	return nil
+}
+
+// DeleteObjectsInOtherNamespaces is deleting children placed outside the owner's namespace. Those have no ownerReferences,
+// so the Kubernetes garbage collector does not delete them together with the owner
+func DeleteObjectsInOtherNamespaces(ctx context.Context, logger *logrus.Entry, mapper meta.ResettableRESTMapper, dynClient dynamic.Interface, refs v1alpha1.ChildrenReferences) error {
+	for _, ref := range refs {
+		if ref.Namespace == "" {
+			continue
+		}
+		deleted, err := deleteOrphan(ctx, mapper, dynClient, ref, ref.Namespace)
+		if err != nil {
+			return errors.Wrapf(err, "cannot delete %s labelled with %s=%s in namespace '%s'", ref.Kind, v1alpha1.LabelTrackingId, ref.TrackingId, ref.Namespace)
+		}
+		for _, name := range deleted {
+			logger.Infof("Deleted %s, kind: %s, %s/%s", ref.APIVersion, ref.Kind, ref.Namespace, name)
+		}
+	}
+	return nil
+}
+
+// findOrphans is returning references from the previous list that are not present in the current list
+func findOrphans(previous v1alpha1.ChildrenReferences, current v1alpha1.ChildrenReferences) v1alpha1.ChildrenReferences {
+	orphans := v1alpha1.ChildrenReferences{}
+	for _, ref := range previous {
+		if !current.Contains(ref) {
+			orphans = append(orphans, ref)
+		}
+	}
+	return orphans
+}
+
+// deleteOrphan is deleting all objects labelled with the tracking id of the reference. Returns names of deleted objects
+func deleteOrphan(ctx context.Context, mapper meta.ResettableRESTMapper, dynClient dynamic.Interface, ref v1alpha1.ChildReference, namespace string) ([]string, error) {
+	c, err := getResourceClient(mapper, dynClient, ref.GetGVK(), namespace)
+	if err != nil {
+		return []string{}, err
+	}
+
+	list, err := c.List(ctx, v1.ListOptions{LabelSelector: v1alpha1.LabelTrackingId + "=" + ref.TrackingId})
+	if err != nil {
+		return []string{}, errors.Wrap(err, "cannot list labelled objects")
+	}
+
+	// Jobs spawned by e.g. CronJob should be deleted together with their parent
+	propagation := v1.DeletePropagationBackground
+	deleted := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		if deleteErr := c.Delete(ctx, item.GetName(), v1.DeleteOptions{PropagationPolicy: &propagation}); deleteErr != nil && !apierrors.IsNotFound(deleteErr) {
+			return deleted, errors.Wrapf(deleteErr, "cannot delete %s", item.GetName())
+		}
+		deleted = append(deleted, item.GetName())
+	}
+	return deleted, nil
+}
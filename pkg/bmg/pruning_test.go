@@ -0,0 +1,52 @@
+package bmg
+
+import (
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindOrphans(t *testing.T) {
+	previous := v1alpha1.ChildrenReferences{
+		// matched by the name, tracking id is regenerated on each apply
+		{APIVersion: "/v1", Kind: "ConfigMap", Name: "app1-backup", TrackingId: "aaa"},
+		// not rendered anymore, e.g. `.spec.cronJob.enabled` switched to false
+		{APIVersion: "batch/v1", Kind: "CronJob", Name: "app1-backup", TrackingId: "bbb"},
+		// matched by the tracking id
+		{APIVersion: "/v1", Kind: "Secret", TrackingId: "ccc"},
+		// legacy reference without a name
+		{APIVersion: "/v1", Kind: "ConfigMap", TrackingId: "ddd"},
+	}
+	current := v1alpha1.ChildrenReferences{
+		{APIVersion: "/v1", Kind: "ConfigMap", Name: "app1-backup", TrackingId: "eee"},
+		{APIVersion: "/v1", Kind: "Secret", Name: "app1-backup", TrackingId: "ccc"},
+	}
+
+	orphans := findOrphans(previous, current)
+
+	assert.Len(t, orphans, 2)
+	assert.Equal(t, "bbb", orphans[0].TrackingId)
+	assert.Equal(t, "ddd", orphans[1].TrackingId)
+}
+
+func TestFindOrphans_SameNameDifferentKindIsAnOrphan(t *testing.T) {
+	previous := v1alpha1.ChildrenReferences{
+		{APIVersion: "/v1", Kind: "Secret", Name: "app1-backup", TrackingId: "aaa"},
+	}
+	current := v1alpha1.ChildrenReferences{
+		{APIVersion: "/v1", Kind: "ConfigMap", Name: "app1-backup", TrackingId: "bbb"},
+	}
+
+	assert.Len(t, findOrphans(previous, current), 1)
+}
+
+func TestFindOrphans_SameNameInOtherNamespaceIsAnOrphan(t *testing.T) {
+	previous := v1alpha1.ChildrenReferences{
+		{APIVersion: "/v1", Kind: "Secret", Name: "app1-restore", Namespace: "staging", TrackingId: "aaa"},
+	}
+	current := v1alpha1.ChildrenReferences{
+		{APIVersion: "/v1", Kind: "Secret", Name: "app1-restore", TrackingId: "bbb"},
+	}
+
+	assert.Len(t, findOrphans(previous, current), 1)
+}
@@ -0,0 +1,70 @@
+package bmg
+
+import (
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// ErrRepositoryNotAccessible means, that the Backup Repository URL is known only inside the Pod, e.g. is missing or uses a shell syntax
+var ErrRepositoryNotAccessible = errors.New("Backup Repository URL is not accessible by the controller")
+
+// RepositoryAccess is the Backup Repository server and collection used by the backup & restore scripts
+type RepositoryAccess struct {
+	URL          string
+	Token        string
+	CollectionId string
+}
+
+// ResolveRepositoryAccess is reading the Backup Repository URL, access token and collection from the same vars, that are used by the backup & restore scripts:
+// .spec.vars merged with vars Secret and the token from .spec.tokenSecretRef. The collection falls back to .spec.collectionId
+func ResolveRepositoryAccess(logger *logrus.Entry, backup *domain.ScheduledBackupAggregate) (RepositoryAccess, error) {
+	access, err := readRepositoryVars(logger, backup)
+	if err != nil {
+		return RepositoryAccess{}, err
+	}
+	if access.URL == "" {
+		return RepositoryAccess{}, errors.Wrap(ErrRepositoryNotAccessible, ".Repository.url is not set")
+	}
+	// ${...} and $(...) are evaluated by the shell inside the Pod
+	if isEvaluatedByShell(access.URL) {
+		return RepositoryAccess{}, errors.Wrapf(ErrRepositoryNotAccessible, ".Repository.url '%s' is evaluated by the shell", access.URL)
+	}
+	if isEvaluatedByShell(access.Token) {
+		return RepositoryAccess{}, errors.Wrap(ErrRepositoryNotAccessible, ".Repository.token is evaluated by the shell, use .spec.tokenSecretRef instead")
+	}
+	if isEvaluatedByShell(access.CollectionId) {
+		return RepositoryAccess{}, errors.Wrapf(ErrRepositoryNotAccessible, ".Repository.collectionId '%s' is evaluated by the shell", access.CollectionId)
+	}
+	return access, nil
+}
+
+// readRepositoryVars is reading the .Repository section of vars as-is, values still could be evaluated by the shell inside the Pod
+func readRepositoryVars(logger *logrus.Entry, backup *domain.ScheduledBackupAggregate) (RepositoryAccess, error) {
+	definition, err := buildDefinition(logger, backup)
+	if err != nil {
+		return RepositoryAccess{}, errors.Wrap(err, "cannot build definition.yaml")
+	}
+	vars := struct {
+		Repository struct {
+			URL          string `yaml:"url"`
+			Token        string `yaml:"token"`
+			CollectionId string `yaml:"collectionId"`
+		} `yaml:"Repository"`
+	}{}
+	if parseErr := yaml.Unmarshal(definition, &vars); parseErr != nil {
+		return RepositoryAccess{}, errors.Wrap(parseErr, "cannot parse .Repository section of vars")
+	}
+
+	access := RepositoryAccess{URL: vars.Repository.URL, Token: vars.Repository.Token, CollectionId: vars.Repository.CollectionId}
+	if access.CollectionId == "" {
+		access.CollectionId = backup.Spec.CollectionId
+	}
+	return access, nil
+}
+
+func isEvaluatedByShell(value string) bool {
+	return strings.Contains(value, "${") || strings.Contains(value, "$(")
+}
@@ -0,0 +1,55 @@
+package bmg
+
+import (
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func TestResolveRepositoryAccess(t *testing.T) {
+	aggregate := &domain.ScheduledBackupAggregate{
+		ScheduledBackup: &v1alpha1.ScheduledBackup{Spec: v1alpha1.ScheduledBackupSpec{
+			CollectionId:  "1111-2222",
+			Vars:          "Repository:\n  url: http://backup-repository.svc:8080\n  token: ignored\n",
+			VarsSecretRef: v1alpha1.VarsSecretSpec{SecretName: "vars"},
+		}},
+		// .spec.tokenSecretRef is put there by the factory
+		AdditionalVarsList: domain.AdditionalVarsList{"Repository.token": []byte("jwt")},
+	}
+
+	access, err := ResolveRepositoryAccess(logrus.WithField("test", true), aggregate)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://backup-repository.svc:8080", access.URL)
+	assert.Equal(t, "jwt", access.Token)
+	assert.Equal(t, "1111-2222", access.CollectionId)
+
+	// vars Secret is overriding .spec.vars
+	aggregate.VarsListSecret = &corev1.Secret{Data: map[string][]byte{"Repository.url": []byte("https://backups.example.org")}}
+	access, err = ResolveRepositoryAccess(logrus.WithField("test", true), aggregate)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://backups.example.org", access.URL)
+
+	// scripts are using the collection from vars
+	aggregate.Spec.Vars += "  collectionId: iwa-ait\n"
+	access, err = ResolveRepositoryAccess(logrus.WithField("test", true), aggregate)
+	assert.Nil(t, err)
+	assert.Equal(t, "iwa-ait", access.CollectionId)
+}
+
+func TestResolveRepositoryAccess_NotAccessible(t *testing.T) {
+	for _, vars := range []v1alpha1.VarsSpec{
+		"Params:\n  db: app\n",
+		"Repository:\n  url: \"${REPOSITORY_URL}\"\n",
+		"Repository:\n  url: http://backup-repository.svc\n  token: \"$(cat /mnt/token)\"\n",
+	} {
+		aggregate := &domain.ScheduledBackupAggregate{
+			ScheduledBackup:    &v1alpha1.ScheduledBackup{Spec: v1alpha1.ScheduledBackupSpec{Vars: vars}},
+			AdditionalVarsList: domain.AdditionalVarsList{},
+		}
+		_, err := ResolveRepositoryAccess(logrus.WithField("test", true), aggregate)
+		assert.ErrorIs(t, err, ErrRepositoryNotAccessible, string(vars))
+	}
+}
@@ -0,0 +1,155 @@
+package bmg
+
+import (
+	"embed"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/riotkit-org/br-backup-maker/generate"
+	"helm.sh/helm/v3/pkg/chart"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+)
+
+// bundledChart are Helm chart templates for kinds not supported by Backup Maker Generator itself, e.g. Argo `kind: Workflow`, Tekton `kind: TaskRun`.
+// Those are using helpers defined in the Backup Maker Generator's chart, and can be overridden by a file of the same name
+//
+//go:embed chart/*
+var bundledChart embed.FS
+
+// resourceVariants are subdirectories of Backup Maker Generator resources. User customizations have priority over the bundled files
+var resourceVariants = []string{"user", ".base"}
+
+// extractionLock is serializing extractions, as Backup Maker Generator is reading the target directory from the process environment
+var extractionLock sync.Mutex
+
+// ExtractResources is unpacking templates and the Helm chart bundled with Backup Maker Generator into a directory.
+// Should be called once on startup - later all renders are only reading the returned fs.FS, so they can run concurrently
+func ExtractResources(dir string) (fs.FS, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "cannot create a directory for Backup Maker Generator resources - %s", dir)
+	}
+	if err := extractInto(dir); err != nil {
+		return nil, errors.Wrapf(err, "cannot extract Backup Maker Generator resources into '%s'", dir)
+	}
+	return os.DirFS(dir), nil
+}
+
+// extractInto is running the Backup Maker Generator's extraction. Its embedded resources are not exported and the extraction
+// always targets $BM_HOME, so the variable is set only for the time of extraction and then restored
+func extractInto(dir string) error {
+	extractionLock.Lock()
+	defer extractionLock.Unlock()
+
+	previous, wasSet := os.LookupEnv("BM_HOME")
+	defer func() {
+		if wasSet {
+			_ = os.Setenv("BM_HOME", previous)
+		} else {
+			_ = os.Unsetenv("BM_HOME")
+		}
+	}()
+	if err := os.Setenv("BM_HOME", dir); err != nil {
+		return errors.Wrap(err, "cannot set BM_HOME")
+	}
+	return generate.ExtractRequiredResources()
+}
+
+// readResource is reading a file e.g. "definition/pg14.json" from "templates" or "chart" directory of Backup Maker Generator resources
+func readResource(resources fs.FS, kind string, name string) ([]byte, error) {
+	for _, variant := range resourceVariants {
+		content, err := fs.ReadFile(resources, path.Join(kind, variant, name))
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, errors.Wrapf(err, "cannot read %s/%s/%s", kind, variant, name)
+		}
+	}
+	return nil, errors.Errorf("cannot find '%s' in Backup Maker Generator %s", name, kind)
+}
+
+// loadChartFiles is loading all templates of the Helm chart that renders Kubernetes resources
+func loadChartFiles(resources fs.FS) ([]*chart.File, error) {
+	for _, variant := range resourceVariants {
+		dir := path.Join("chart", variant)
+		entries, err := fs.ReadDir(resources, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot list Chart templates at path '%s'", dir)
+		}
+
+		var loaded []*chart.File
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			content, readErr := fs.ReadFile(resources, path.Join(dir, entry.Name()))
+			if readErr != nil {
+				return nil, errors.Wrapf(readErr, "cannot read Chart template at path '%s'", path.Join(dir, entry.Name()))
+			}
+			loaded = append(loaded, &chart.File{Name: entry.Name(), Data: content})
+		}
+		return appendBundledChartFiles(loaded)
+	}
+	return nil, errors.New("cannot find Backup Maker Generator Helm chart")
+}
+
+// appendBundledChartFiles is adding templates bundled with the controller, that are not present in the Backup Maker Generator's chart
+func appendBundledChartFiles(loaded []*chart.File) ([]*chart.File, error) {
+	present := make(map[string]bool, len(loaded))
+	for _, file := range loaded {
+		present[file.Name] = true
+	}
+	entries, err := bundledChart.ReadDir("chart")
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list Chart templates bundled with the controller")
+	}
+	for _, entry := range entries {
+		if present[entry.Name()] {
+			continue
+		}
+		content, readErr := bundledChart.ReadFile(path.Join("chart", entry.Name()))
+		if readErr != nil {
+			return nil, errors.Wrapf(readErr, "cannot read Chart template bundled with the controller '%s'", entry.Name())
+		}
+		loaded = append(loaded, &chart.File{Name: entry.Name(), Data: content})
+	}
+	return loaded, nil
+}
+
+// resolveTemplate is returning a template definition and a script for selected operation.
+// ClusterBackupProcedureTemplate and BackupProcedureTemplate are providing the script directly,
+// internal templates are read from resources bundled with Backup Maker Generator
+func resolveTemplate(resources fs.FS, template domain.Template, operation domain.Operation) (generate.TemplateDefinition, string, error) {
+	if template.ProvidesScript() {
+		script := template.GetRestoreScript()
+		if operation == domain.Backup {
+			script = template.GetBackupScript()
+		}
+		return generate.TemplateDefinition{DefaultImage: template.GetImage()}, script, nil
+	}
+
+	definitionContent, err := readResource(resources, "templates", "definition/"+template.GetName()+".json")
+	if err != nil {
+		return generate.TemplateDefinition{}, "", errors.Wrapf(err, "cannot load definition of internal template '%s'", template.GetName())
+	}
+	tplDef := generate.TemplateDefinition{}
+	if unmarshalErr := json.Unmarshal(definitionContent, &tplDef); unmarshalErr != nil {
+		return generate.TemplateDefinition{}, "", errors.Wrapf(unmarshalErr, "cannot parse definition of internal template '%s'", template.GetName())
+	}
+
+	scriptName := tplDef.RestoreTemplate
+	if operation == domain.Backup {
+		scriptName = tplDef.BackupTemplate
+	}
+	script, err := readResource(resources, "templates", string(operation)+"/"+scriptName)
+	if err != nil {
+		return generate.TemplateDefinition{}, "", errors.Wrapf(err, "cannot load %s script of internal template '%s'", operation, template.GetName())
+	}
+	return tplDef, string(script), nil
+}
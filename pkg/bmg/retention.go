@@ -0,0 +1,181 @@
+package bmg
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/sirupsen/logrus"
+	"io/fs"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
+	"strings"
+	"time"
+)
+
+// Environment variables of the retention Job, read by `prune` command. $VAR and ${VAR} references are expanded from the Pod's environment
+const (
+	EnvRepositoryURL          = "BACKUP_REPOSITORY_URL"
+	EnvRepositoryToken        = "BACKUP_REPOSITORY_TOKEN"
+	EnvRepositoryCollectionId = "BACKUP_REPOSITORY_COLLECTION_ID"
+)
+
+// retentionJobTTL is how long a finished retention Job is kept, so its logs could be read
+const retentionJobTTL = int64(24 * 60 * 60)
+
+// ApplyRetentionJob is creating a Job, that applies .spec.retention from inside the backup Pod's environment. Returns name of the Job.
+// The Job is named after the backup that triggered it, so applying it again for the same backup does not spawn a second Job
+func ApplyRetentionJob(ctx context.Context, logger *logrus.Entry, recorder record.EventRecorder, mapper meta.ResettableRESTMapper, dynClient dynamic.Interface,
+	resources fs.FS, backup *domain.ScheduledBackupAggregate, image string, pinned []string, backupFinishedAt time.Time) (string, error) {
+
+	job, err := RenderRetentionJob(logger, resources, backup, image, pinned)
+	if err != nil {
+		return "", err
+	}
+	job.SetName(retentionJobName(backup.Name, backupFinishedAt))
+	if applyErr := CreateOrUpdate(ctx, recorder, dynClient, mapper, job, backup.ScheduledBackup, ConflictPolicyForce); applyErr != nil {
+		return "", errors.Wrap(applyErr, "cannot apply retention Job to the cluster")
+	}
+	return job.GetName(), nil
+}
+
+// RenderRetentionJob is rendering a Job, that applies .spec.retention using the controller's image with `prune` command.
+// It is used, when the Backup Repository is known only inside the backup Pod - e.g. the URL is taken from the Pod's environment.
+// The Job is a copy of the backup Job with its environment, volumes and service account, but only with the first container.
+// Pinned versions are selected by restores, that are not finished yet - those are never deleted by the Job
+func RenderRetentionJob(logger *logrus.Entry, resources fs.FS, backup *domain.ScheduledBackupAggregate, image string, pinned []string) (*unstructured.Unstructured, error) {
+	policy := backup.Spec.Retention
+	if policy == nil {
+		return nil, errors.New(".spec.retention is not set")
+	}
+	access, err := readRepositoryVars(logger, backup)
+	if err != nil {
+		return nil, err
+	}
+	if access.URL == "" {
+		return nil, errors.New(".Repository.url is not set")
+	}
+	// $(...) would be also treated by Kubernetes as a reference to other environment variable
+	if strings.Contains(access.URL+access.Token+access.CollectionId, "$(") {
+		return nil, errors.New(".Repository section of vars is using a command substitution, which is not supported by the retention Job")
+	}
+
+	// the backup is rendered as a Job, the same way as RequestedBackupAction is running it
+	asJob := *backup
+	asJob.AdditionalVarsList = domain.AdditionalVarsList{"HelmValues.kindType": []byte(v1alpha1.KindTypeJob)}
+	for key, value := range backup.AdditionalVarsList {
+		if key != "HelmValues.kindType" {
+			asJob.AdditionalVarsList[key] = value
+		}
+	}
+	rendered, renderErr := RenderKubernetesResourcesForOperation(logger, resources, &asJob, domain.Backup,
+		domain.NewResourceTypesFilterForRequestedBackupAction([]schema.GroupVersionKind{batchv1.SchemeGroupVersion.WithKind("Job")}))
+	if renderErr != nil {
+		return nil, errors.Wrap(renderErr, "cannot render backup Job")
+	}
+	var job *unstructured.Unstructured
+	for i := range rendered {
+		if rendered[i].GetKind() == "Job" {
+			job = &rendered[i]
+			break
+		}
+	}
+	if job == nil {
+		return nil, errors.New("the template does not render a Job, is .HelmValues.kindType overridden?")
+	}
+
+	containers, _, _ := unstructured.NestedSlice(job.Object, "spec", "template", "spec", "containers")
+	if len(containers) == 0 {
+		return nil, errors.Errorf("rendered Job '%s' does not have any container", job.GetName())
+	}
+	container := containers[0].(map[string]interface{})
+	env, _, _ := unstructured.NestedSlice(container, "env")
+	env = append(env,
+		map[string]interface{}{"name": EnvRepositoryURL, "value": access.URL},
+		repositoryTokenEnv(backup, access.Token),
+		map[string]interface{}{"name": EnvRepositoryCollectionId, "value": access.CollectionId},
+	)
+	delete(container, "workingDir")
+	container["name"] = "retention"
+	container["image"] = image
+	container["command"] = []interface{}{"/manager", "prune"}
+	container["args"] = retentionArgs(backup, pinned)
+	container["env"] = env
+
+	podSpec, _, _ := unstructured.NestedMap(job.Object, "spec", "template", "spec")
+	delete(podSpec, "initContainers")
+	podSpec["containers"] = []interface{}{container}
+	podSpec["restartPolicy"] = "Never"
+	if setErr := unstructured.SetNestedMap(job.Object, podSpec, "spec", "template", "spec"); setErr != nil {
+		return nil, errors.Wrap(setErr, "cannot replace containers of the retention Job")
+	}
+	if setErr := unstructured.SetNestedField(job.Object, retentionJobTTL, "spec", "ttlSecondsAfterFinished"); setErr != nil {
+		return nil, errors.Wrap(setErr, "cannot set .spec.ttlSecondsAfterFinished of the retention Job")
+	}
+	addNamespace(job, backup.Namespace)
+	addOwnerReferences(logger, job, backup)
+	return job, nil
+}
+
+// retentionArgs are converting .spec.retention into arguments of `prune` command
+func retentionArgs(backup *domain.ScheduledBackupAggregate, pinned []string) []interface{} {
+	policy := backup.Spec.Retention
+	var args []interface{}
+	for _, rule := range []struct {
+		flag  string
+		value int
+	}{{"--keep-last", policy.KeepLast}, {"--keep-daily", policy.KeepDaily}, {"--keep-weekly", policy.KeepWeekly}, {"--keep-monthly", policy.KeepMonthly}} {
+		if rule.value > 0 {
+			args = append(args, fmt.Sprintf("%s=%d", rule.flag, rule.value))
+		}
+	}
+	if policy.MaxAge != nil {
+		args = append(args, "--max-age="+policy.MaxAge.Duration.String())
+	}
+	if policy.DryRun {
+		args = append(args, "--dry-run")
+	}
+	for _, version := range pinned {
+		args = append(args, "--pin="+version)
+	}
+	return args
+}
+
+// repositoryTokenEnv is referring to the Secret the token comes from, so the token is not stored in the Job's spec
+func repositoryTokenEnv(backup *domain.ScheduledBackupAggregate, token string) map[string]interface{} {
+	env := map[string]interface{}{"name": EnvRepositoryToken}
+	secretKeyRef := func(name string, key string) map[string]interface{} {
+		return map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": name, "key": key}}
+	}
+	importOnlyKeys := backup.Spec.VarsSecretRef.ImportOnlyKeys
+
+	switch {
+	case token == "":
+		env["value"] = ""
+	// vars Secret is merged last, so it has the precedence
+	case backup.VarsListSecret != nil && string(backup.VarsListSecret.Data["Repository.token"]) == token &&
+		(len(importOnlyKeys) == 0 || contains(importOnlyKeys, "Repository.token")):
+		env["valueFrom"] = secretKeyRef(backup.Spec.VarsSecretRef.SecretName, "Repository.token")
+	case backup.TokenSecret != nil && string(backup.TokenSecret.Data[backup.Spec.TokenSecretRef.TokenKey]) == token:
+		env["valueFrom"] = secretKeyRef(backup.Spec.TokenSecretRef.SecretName, backup.Spec.TokenSecretRef.TokenKey)
+	default:
+		// taken from .spec.vars, which is not a secret anyway, or a reference to the Pod's environment
+		env["value"] = token
+	}
+	return env
+}
+
+// retentionJobName is unique for each backup, that triggers the retention
+func retentionJobName(scheduledBackupName string, backupFinishedAt time.Time) string {
+	suffix := fmt.Sprintf("-retention-%d", backupFinishedAt.Unix())
+	// Job name is limited by the `job-name` label of its Pods
+	if maxPrefix := 63 - len(suffix); len(scheduledBackupName) > maxPrefix {
+		scheduledBackupName = strings.TrimRight(scheduledBackupName[:maxPrefix], "-")
+	}
+	return scheduledBackupName + suffix
+}
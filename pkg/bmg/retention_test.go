@@ -0,0 +1,82 @@
+package bmg
+
+import (
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"testing"
+	"time"
+)
+
+func createAggregateWithRetention(vars v1alpha1.VarsSpec) *domain.ScheduledBackupAggregate {
+	return &domain.ScheduledBackupAggregate{
+		ScheduledBackup: &v1alpha1.ScheduledBackup{
+			TypeMeta:   v1.TypeMeta{APIVersion: "riotkit.org/v1alpha1", Kind: "ScheduledBackup"},
+			ObjectMeta: v1.ObjectMeta{Name: "app1", Namespace: "team-a", UID: "uid-1"},
+			Spec: v1alpha1.ScheduledBackupSpec{
+				Operation:       "backup",
+				CollectionId:    "iwa-ait",
+				Vars:            vars,
+				GPGKeySecretRef: v1alpha1.GPGKeySecretSpec{PublicKey: "public", PrivateKey: "private"},
+				TokenSecretRef:  v1alpha1.TokenSecretSpec{SecretName: "backup-token", TokenKey: "jwt"},
+				Retention:       &v1alpha1.RetentionSpec{KeepLast: 3, KeepMonthly: 6, MaxAge: &v1.Duration{Duration: time.Hour * 24 * 365}, DryRun: true},
+			},
+		},
+		Template:           domain.InternalTemplate{Name: "pg14"},
+		GPGSecret:          &corev1.Secret{Data: map[string][]byte{"public": []byte("PUBLIC"), "private": []byte("PRIVATE")}},
+		TokenSecret:        &corev1.Secret{Data: map[string][]byte{"jwt": []byte("secret-jwt")}},
+		AdditionalVarsList: domain.AdditionalVarsList{"Repository.token": []byte("secret-jwt")},
+	}
+}
+
+func TestRenderRetentionJob(t *testing.T) {
+	aggregate := createAggregateWithRetention("Repository:\n  url: \"${REPOSITORY_URL}\"\nParams:\n  hostname: postgres\n")
+
+	job, err := RenderRetentionJob(logrus.WithField("test", true), testResources, aggregate, "ghcr.io/riotkit-org/backup-maker-controller:1.0", []string{"v2"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Job", job.GetKind())
+	assert.Equal(t, "team-a", job.GetNamespace())
+	ownerReferences := job.Object["metadata"].(map[string]interface{})["ownerReferences"].([]map[string]interface{})
+	assert.Equal(t, "app1", ownerReferences[0]["name"])
+
+	containers, _, _ := unstructured.NestedSlice(job.Object, "spec", "template", "spec", "containers")
+	assert.Len(t, containers, 1)
+	container := containers[0].(map[string]interface{})
+	assert.Equal(t, "ghcr.io/riotkit-org/backup-maker-controller:1.0", container["image"])
+	assert.Equal(t, []interface{}{"/manager", "prune"}, container["command"])
+	assert.Equal(t, []interface{}{"--keep-last=3", "--keep-monthly=6", "--max-age=8760h0m0s", "--dry-run", "--pin=v2"}, container["args"])
+
+	// the URL is expanded by `prune` command from the Pod's environment, the token is not copied into the Job
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": EnvRepositoryURL, "value": "${REPOSITORY_URL}"},
+		map[string]interface{}{"name": EnvRepositoryToken, "valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "backup-token", "key": "jwt"}}},
+		map[string]interface{}{"name": EnvRepositoryCollectionId, "value": "iwa-ait"},
+	}, container["env"])
+
+	ttl, _, _ := unstructured.NestedInt64(job.Object, "spec", "ttlSecondsAfterFinished")
+	assert.Equal(t, retentionJobTTL, ttl)
+}
+
+func TestRenderRetentionJob_NotPossible(t *testing.T) {
+	for vars, expectedErr := range map[v1alpha1.VarsSpec]string{
+		"Params:\n  hostname: postgres\n":                                          ".Repository.url is not set",
+		"Repository:\n  url: \"$(cat /mnt/url)\"\nParams:\n  hostname: postgres\n": "command substitution",
+	} {
+		_, err := RenderRetentionJob(logrus.WithField("test", true), testResources, createAggregateWithRetention(vars), "controller:1.0", nil)
+		assert.ErrorContains(t, err, expectedErr, string(vars))
+	}
+}
+
+func TestRetentionJobName(t *testing.T) {
+	finishedAt := time.Date(2023, 1, 2, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, "app1-retention-1672624800", retentionJobName("app1", finishedAt))
+
+	long := retentionJobName("a-very-long-name-of-scheduled-backup-that-takes-the-whole-limit", finishedAt)
+	// the name is cut at 63 characters, and a trailing dash is removed
+	assert.Equal(t, "a-very-long-name-of-scheduled-backup-that-retention-1672624800", long)
+}
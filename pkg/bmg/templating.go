@@ -1,7 +1,6 @@
 package bmg
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/ohler55/ojg/jp"
 	"github.com/pkg/errors"
@@ -9,14 +8,15 @@ import (
 	"github.com/riotkit-org/br-backup-maker/generate"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
+	"io/fs"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apiyaml "k8s.io/apimachinery/pkg/runtime/serializer/yaml"
-	"os"
-	"path/filepath"
+	"math"
 	"strings"
+	"time"
 )
 
-func RenderKubernetesResourcesFor(logger *logrus.Entry, backup domain.Renderable) ([]unstructured.Unstructured, error) {
+func RenderKubernetesResourcesFor(logger *logrus.Entry, resources fs.FS, backup domain.Renderable) ([]unstructured.Unstructured, error) {
 	//
 	// Render helper resources for all operation types: "restore" + "backup"
 	// This in effect will create e.g. separate ConfigMap, Secret for "restore" and separate for "backup"
@@ -27,136 +27,148 @@ func RenderKubernetesResourcesFor(logger *logrus.Entry, backup domain.Renderable
 	if backup.ShouldRenderDependentObjectsForAllOperationTypes() {
 		var rendered []unstructured.Unstructured
 		for _, operation := range []domain.Operation{domain.Backup, domain.Restore} {
-			opRendered, renderErr := RenderKubernetesResourcesForOperation(logger, backup, operation,
-				domain.NewResourceTypesFilterForScheduledBackup(backup, operation))
+			opRendered, renderErr := RenderKubernetesResourcesForOperation(logger, resources, backup, operation,
+				domain.NewResourceTypesFilterForScheduledBackup())
 
 			if renderErr != nil {
 				return []unstructured.Unstructured{}, renderErr
 			}
 			rendered = append(rendered, opRendered...)
 		}
+
+		// Each schedule e.g. a daily backup and a weekly verification restore is getting its own CronJob
+		for _, schedule := range backup.GetSchedules() {
+			scheduled := domain.NewScheduleRenderable(backup, schedule)
+			scheduleRendered, renderErr := RenderKubernetesResourcesForOperation(logger, resources, scheduled, scheduled.GetOperation(),
+				domain.NewResourceTypesFilterForSchedule())
+
+			if renderErr != nil {
+				return []unstructured.Unstructured{}, errors.Wrapf(renderErr, "cannot render CronJob for schedule '%s'", schedule.GetCronJobName(backup.GetScheduledBackup().Name))
+			}
+			if len(scheduleRendered) == 0 {
+				return []unstructured.Unstructured{}, errors.Errorf("the template did not render any CronJob for schedule '%s', is .HelmValues.kindType overridden?", schedule.GetCronJobName(backup.GetScheduledBackup().Name))
+			}
+			rendered = append(rendered, scheduleRendered...)
+		}
 		return rendered, nil
 	}
 	//
 	// Render runtime resources e.g. `kind: Job` that runs immediately
+	// A restore into a different target is rendering also its own helper objects
 	//
-	return RenderKubernetesResourcesForOperation(logger, backup, backup.GetOperation(),
-		domain.NewResourceTypesFilterForRequestedBackupAction())
+	return RenderKubernetesResourcesForOperation(logger, resources, backup, backup.GetOperation(), domain.NewResourceTypes(backup.AcceptedResourceTypes()))
 }
 
-// RenderKubernetesResourcesForOperation is rendering Kubernetes resources like CronJob, Job, Secret, ConfigMap using Backup Maker Generator (BMG), which is using Helm under the hood
-func RenderKubernetesResourcesForOperation(logger *logrus.Entry, backup domain.Renderable,
+// RenderKubernetesResourcesForOperation is rendering Kubernetes resources like CronJob, Job, Secret, ConfigMap using Backup Maker Generator (BMG), which is using Helm under the hood.
+// Everything is rendered in memory - the only files read are Backup Maker Generator resources, so multiple renders can safely run at the same time
+func RenderKubernetesResourcesForOperation(logger *logrus.Entry, resources fs.FS, backup domain.Renderable,
 	operation domain.Operation, acceptedResourceTypes domain.ResourceTypes) ([]unstructured.Unstructured, error) {
 
 	logger.Debugln("RenderKubernetesResourcesForOperation()")
 
-	// Create a temporary workspace directory
-	pwd, _ := os.Getwd()
-	defer func(dir string) {
-		_ = os.Chdir(dir)
-	}(pwd)
-
-	dir, tempDirErr := os.MkdirTemp("/tmp", "bmg")
-	if tempDirErr != nil {
-		return []unstructured.Unstructured{}, errors.Wrap(tempDirErr, "cannot create a temporary directory to run Backup Maker Generator (BMG)")
+	// Backup/restore procedure: either from `kind: ClusterBackupProcedureTemplate` or bundled with BMG
+	tplDef, script, resolveErr := resolveTemplate(resources, backup.GetTemplate(), operation)
+	if resolveErr != nil {
+		return []unstructured.Unstructured{}, errors.Wrap(resolveErr, "cannot resolve backup procedure template")
 	}
-	_ = os.MkdirAll(dir+"/output", 0755)
 
-	// Delete temporary directory after the generating ends
-	defer func(path string) {
-		_ = os.RemoveAll(path)
-	}(dir)
+	// GPG key: public key for backup, private key for restore
+	gpgKey := selectGPGKey(backup.GetBackupAggregate(), operation)
 
-	// Write backup/restore procedure template
-	// Extracts `kind: ClusterBackupProcedureTemplate` into a local file
-	_ = os.MkdirAll("./templates/"+string(operation), 0755)
-	templatePath := "./templates/" + string(operation) + "/" + backup.GetTemplate().GetName() + ".tmpl"
-	if writeErr := writeTemplate(logger, backup.GetTemplate(), operation, templatePath); writeErr != nil {
-		return []unstructured.Unstructured{}, errors.Wrap(writeErr, fmt.Sprintf("cannot write template at path '%s'", templatePath))
+	// Vars from .spec.vars merged with vars from Secret, same as definition.yaml in BMG
+	definition, definitionErr := buildDefinition(logger, backup.GetBackupAggregate())
+	if definitionErr != nil {
+		return []unstructured.Unstructured{}, errors.Wrap(definitionErr, "cannot build definition.yaml")
 	}
-
-	// Write GPG to desired temporary path
-	gpgPath := dir + "/gpg.key"
-	if writeErr := writeGPGKey(backup.GetBackupAggregate(), gpgPath, operation); writeErr != nil {
-		return []unstructured.Unstructured{}, errors.Wrap(writeErr, "cannot write GPG key to temporary file")
+	userCfg := generate.Config{}
+	if parseErr := yaml.Unmarshal(definition, &userCfg); parseErr != nil {
+		return []unstructured.Unstructured{}, errors.Wrap(parseErr, "cannot parse definition.yaml, invalid format or invalid values types?")
 	}
+	cfg := generate.NewComputedConfiguration(tplDef, userCfg)
+	cfg.GetConfig().Repository["encryptionKeyPath"] = gpgKeyMountPath
 
-	// Write vars into definition.yaml
-	definitionPath := dir + "/definition.yaml"
-	if writeErr := writeDefinition(logger, backup.GetBackupAggregate(), definitionPath); writeErr != nil {
-		return []unstructured.Unstructured{}, errors.Wrap(writeErr, "cannot write definition.yaml to temporary file")
+	// Run BMG templating to generate YAML manifests
+	renderedScript, scriptErr := renderScript(backup.GetTemplate().GetName(), script, cfg)
+	if scriptErr != nil {
+		return []unstructured.Unstructured{}, errors.Wrap(scriptErr, "error while generating manifests")
 	}
-
-	// Run BMG to generate YAML manifests
-	if err := generate.ExtractRequiredResources(); err != nil {
-		return []unstructured.Unstructured{}, errors.Wrap(err, "cannot populate ~/.bm with templates")
+	chartFiles, chartErr := loadChartFiles(resources)
+	if chartErr != nil {
+		return []unstructured.Unstructured{}, errors.Wrap(chartErr, "error while generating manifests")
+	}
+	image := backup.GetTemplate().GetImage()
+	if image == "" {
+		image = cfg.GetDefaultImage()
 	}
-	cmd := generate.SnippetGenerationCommand{
-		TemplateName:   backup.GetTemplate().GetName(),
-		UserConfigFile: definitionPath,
-		IsKubernetes:   true,
-		KeyPath:        gpgPath,
-		OutputDir:      dir + "/output",
-		Schedule:       backup.GetScheduledBackup().Spec.CronJob.ScheduleEvery,
-		JobName:        backup.GetScheduledBackup().Name,
-		Image:          backup.GetTemplate().GetImage(),
-		Operation:      string(operation),
-		Namespace:      backup.GetScheduledBackup().Namespace,
+	// a single schedule is always rendering a CronJob, no matter which operation it performs
+	scheduleEvery, kindType := backup.GetScheduledBackup().Spec.CronJob.ScheduleEvery, ""
+	if schedule := backup.GetSchedule(); schedule != nil {
+		scheduleEvery, kindType = schedule.ScheduleEvery, "CronJob"
 	}
-	genErr := cmd.Run()
+	content, genErr := renderChart(chartFiles, chartParams{
+		Script:    renderedScript,
+		GPGKey:    string(gpgKey),
+		Schedule:  scheduleEvery,
+		JobName:   backup.GetResourceName(),
+		Image:     image,
+		Namespace: backup.GetTargetNamespace(),
+		Operation: operation,
+		KindType:  kindType,
+		Env:       backup.GetExtraEnv(),
+	}, cfg)
 	if genErr != nil {
 		return []unstructured.Unstructured{}, errors.Wrap(genErr, "error while generating manifests")
 	}
 
-	// read what was generated
-	manifestsPath := dir + "/output/" + string(operation) + ".yaml"
-	content, readErr := os.ReadFile(manifestsPath)
-	if readErr != nil {
-		return []unstructured.Unstructured{}, errors.Wrap(readErr, fmt.Sprintf(
-			"cannot read rendered manifest file at path '%s'", manifestsPath))
+	objects, parseErr := parseRenderedManifests(logger, content, acceptedResourceTypes)
+	if parseErr != nil {
+		return []unstructured.Unstructured{}, parseErr
 	}
-
-	return parseRenderedManifests(logger, string(content), acceptedResourceTypes)
-}
-
-// writeTemplate is writing the backup/restore procedure template
-func writeTemplate(logger *logrus.Entry, template domain.Template, operation domain.Operation, path string) error {
-	if !template.ProvidesScript() {
-		logger.Debugln("Skipping script provision: !ProvidesScript()")
-		return nil
-	}
-	logger.Debugf("writeTemplate(name: %v)", template)
-
-	content := template.GetRestoreScript()
-	if operation == domain.Backup {
-		content = template.GetBackupScript()
+	if schedule := backup.GetSchedule(); schedule != nil {
+		nameCronJobs(objects, schedule.GetCronJobName(backup.GetScheduledBackup().Name))
 	}
-
-	// write JSON metadata file
-	tplDef := generate.TemplateDefinition{
-		DefaultImage: "this-should-be-overridden", // image is always overridden, so we do not set default value
-
-		// operator writes both scripts under same name, but different subdirectories of course
-		RestoreTemplate: template.GetName() + ".tmpl",
-		BackupTemplate:  template.GetName() + ".tmpl",
-	}
-	defAsStr, defMarshalErr := json.Marshal(&tplDef)
-	if defMarshalErr != nil {
-		return errors.Wrap(defMarshalErr, "cannot marshal TemplateDefinition{} to JSON")
+	if timeout := backup.GetTimeout(); timeout != nil {
+		if timeoutErr := applyTimeout(objects, timeout.Duration); timeoutErr != nil {
+			return []unstructured.Unstructured{}, timeoutErr
+		}
 	}
+	return objects, nil
+}
 
-	definitionDir := filepath.Dir(path) + "/../definition"
-	if mkdirErr := os.MkdirAll(definitionDir, 0700); mkdirErr != nil {
-		return errors.Wrapf(mkdirErr, "cannot create definition dir - %s", definitionDir)
+// nameCronJobs is giving CronJobs a name of the schedule. Helper objects rendered by the chart keep their names, as those are shared by all schedules of the same operation
+func nameCronJobs(objects []unstructured.Unstructured, name string) {
+	for i := range objects {
+		if objects[i].GetKind() == "CronJob" {
+			objects[i].SetName(name)
+		}
 	}
+}
 
-	definitionPath := definitionDir + "/" + template.GetName() + ".json"
-	logger.Debugf("Writing a JSON definition file: %s", definitionPath)
-	if writeErr := os.WriteFile(definitionPath, defAsStr, 0700); writeErr != nil {
-		return errors.Wrapf(writeErr, "cannot write a file - %s", definitionPath)
+// applyTimeout is setting `activeDeadlineSeconds`, so Kubernetes kills a run that lasts too long.
+// For a CronJob the deadline is set on each spawned Job, an Argo Workflow has the same field for the whole Workflow.
+// Tekton is using a duration string instead
+func applyTimeout(objects []unstructured.Unstructured, timeout time.Duration) error {
+	seconds := int64(math.Ceil(timeout.Seconds()))
+	for _, obj := range objects {
+		var path []string
+		var value interface{} = seconds
+		switch obj.GroupVersionKind().Kind {
+		case "Job", "Pod", "Workflow":
+			path = []string{"spec", "activeDeadlineSeconds"}
+		case "CronJob":
+			path = []string{"spec", "jobTemplate", "spec", "activeDeadlineSeconds"}
+		case "TaskRun":
+			path, value = []string{"spec", "timeout"}, fmt.Sprintf("%ds", seconds)
+		case "PipelineRun":
+			path, value = []string{"spec", "timeouts", "pipeline"}, fmt.Sprintf("%ds", seconds)
+		default:
+			continue
+		}
+		if err := unstructured.SetNestedField(obj.Object, value, path...); err != nil {
+			return errors.Wrapf(err, "cannot set .%s on %s '%s'", strings.Join(path, "."), obj.GetKind(), obj.GetName())
+		}
 	}
-
-	return os.WriteFile(path, []byte(content), 0700)
+	return nil
 }
 
 // parseRenderedManifests is reading manifests from YAML into []UnstructuredObject
@@ -206,22 +218,37 @@ func parseRenderedManifests(logger *logrus.Entry, content string, kindsToRender
 	return objects, nil
 }
 
-// writeGPGKey is extracting a proper GPG key from Kubernetes Secret and writing down to the temporary file
-func writeGPGKey(backup *domain.ScheduledBackupAggregate, writeToPath string, operation domain.Operation) error {
+// selectGPGKey is extracting a proper GPG key from Kubernetes Secret - public key to encrypt backups, private key to decrypt them on restore
+func selectGPGKey(backup *domain.ScheduledBackupAggregate, operation domain.Operation) []byte {
 	keyName := backup.Spec.GPGKeySecretRef.PrivateKey
-	backup.AdditionalVarsList["HelmValues.gpgKeyContent"] = backup.GPGSecret.Data[backup.Spec.GPGKeySecretRef.PrivateKey]
 	if operation == domain.Backup {
 		keyName = backup.Spec.GPGKeySecretRef.PublicKey
-		backup.AdditionalVarsList["HelmValues.gpgKeyContent"] = backup.GPGSecret.Data[backup.Spec.GPGKeySecretRef.PublicKey]
 	}
-	return os.WriteFile(writeToPath, backup.GPGSecret.Data[keyName], 0700)
+	backup.AdditionalVarsList["HelmValues.gpgKeyContent"] = backup.GPGSecret.Data[keyName]
+	return backup.GPGSecret.Data[keyName]
 }
 
-// writeDefinition is writing the definition.yaml into the workspace
-func writeDefinition(logger *logrus.Entry, backup *domain.ScheduledBackupAggregate, writeToPath string) error {
+// ParseVars is parsing .spec.vars, which is a YAML document
+func ParseVars(content string) (map[string]interface{}, error) {
 	var vars map[string]interface{}
-	if err := yaml.Unmarshal([]byte(backup.Spec.Vars), &vars); err != nil {
-		return errors.Wrap(err, "cannot parse .spec.vars as YAML")
+	if err := yaml.Unmarshal([]byte(content), &vars); err != nil {
+		return nil, errors.Wrap(err, "cannot parse .spec.vars as YAML")
+	}
+	return vars, nil
+}
+
+// buildDefinition is building the definition.yaml content - user configuration for Backup Maker Generator
+func buildDefinition(logger *logrus.Entry, backup *domain.ScheduledBackupAggregate) ([]byte, error) {
+	vars, err := ParseVars(string(backup.Spec.Vars))
+	if err != nil {
+		return nil, err
+	}
+	if backup.VarsOverrides != "" {
+		overrides, overridesErr := ParseVars(string(backup.VarsOverrides))
+		if overridesErr != nil {
+			return nil, errors.Wrap(overridesErr, "cannot parse vars overrides")
+		}
+		vars = MergeVars(vars, overrides)
 	}
 
 	type VarSource struct {
@@ -252,10 +279,10 @@ func writeDefinition(logger *logrus.Entry, backup *domain.ScheduledBackupAggrega
 			logger.Debugf("Setting '%s' -> '%v'", path, string(value))
 			expression, jpErr := jp.ParseString("$." + path)
 			if jpErr != nil {
-				return errors.Wrap(jpErr, fmt.Sprintf("cannot parse dot-notation path to convert from some.path.dot format. Name: '%s'", path))
+				return nil, errors.Wrap(jpErr, fmt.Sprintf("cannot parse dot-notation path to convert from some.path.dot format. Name: '%s'", path))
 			}
 			if setErr := expression.Set(vars, string(value)); setErr != nil {
-				return errors.Wrap(setErr, fmt.Sprintf("cannot merge value from Secret into the vars, name: '%s'", path))
+				return nil, errors.Wrap(setErr, fmt.Sprintf("cannot merge value from Secret into the vars, name: '%s'", path))
 			}
 		}
 	}
@@ -264,9 +291,26 @@ func writeDefinition(logger *logrus.Entry, backup *domain.ScheduledBackupAggrega
 	asYaml, marshalingErr := yaml.Marshal(vars)
 	logger.Debug(string(asYaml))
 	if marshalingErr != nil {
-		return errors.Wrap(marshalingErr, "cannot serialize vars to YAML as a definition.yaml")
+		return nil, errors.Wrap(marshalingErr, "cannot serialize vars to YAML as a definition.yaml")
+	}
+	return asYaml, nil
+}
+
+// MergeVars is merging overrides on top of vars. Nested maps are merged key by key, any other value is replaced
+func MergeVars(vars map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	if vars == nil {
+		vars = make(map[string]interface{})
+	}
+	for key, override := range overrides {
+		overrideMap, overrideIsMap := override.(map[string]interface{})
+		existingMap, existingIsMap := vars[key].(map[string]interface{})
+		if overrideIsMap && existingIsMap {
+			vars[key] = MergeVars(existingMap, overrideMap)
+			continue
+		}
+		vars[key] = override
 	}
-	return os.WriteFile(writeToPath, asYaml, 0700)
+	return vars
 }
 
 func contains(s []string, e string) bool {
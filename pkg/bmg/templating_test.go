@@ -4,20 +4,70 @@ import (
 	"context"
 	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
 	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/riotkit-org/br-backup-maker/generate"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"os"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
-// TestWriteTemplate is checking if the template is written, when using ClusterBackupProcedureTemplate
-func TestWriteTemplate_ClusterBackupProcedureTemplate(t *testing.T) {
-	dir, err := os.MkdirTemp("/tmp", "br-fdi")
-	if err != nil {
-		logrus.Fatal(err)
-	}
+// testRunnableKinds are kinds of all integrations, as if all of them were enabled in the controller
+var testRunnableKinds = []schema.GroupVersionKind{
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "", Version: "v1", Kind: "Pod"},
+	{Group: "argoproj.io", Version: "v1alpha1", Kind: "Workflow"},
+	{Group: "tekton.dev", Version: "v1", Kind: "TaskRun"},
+	{Group: "tekton.dev", Version: "v1", Kind: "PipelineRun"},
+}
 
+// testResources are minimal Backup Maker Generator resources, kept in memory
+var testResources = fstest.MapFS{
+	"templates/.base/definition/pg14.json":  {Data: []byte(`{"defaultImage": "ghcr.io/riotkit-org/pgbr:pg14", "backupTemplate": "postgres.tmpl", "restoreTemplate": "postgres.tmpl"}`)},
+	"templates/.base/backup/postgres.tmpl":  {Data: []byte("pg_dumpall -h {{ .Params.hostname }}")},
+	"templates/.base/restore/postgres.tmpl": {Data: []byte("psql -h {{ .Params.hostname }}")},
+	"chart/.base/configmap.yaml": {Data: []byte(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: "{{ .Values.name }}-{{ .Values.operationType }}"
+data:
+    {{ .Values.scriptName }}: |
+        {{- .Values.scriptContent | nindent 8 }}
+`)},
+	"chart/.base/job.yaml": {Data: []byte(`{{- if eq .Values.kindType "Job" }}
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+    name: "{{ .Values.name }}-{{ .Values.operationType }}"
+spec:
+    template:
+        spec:
+            containers:
+                - name: backup
+                  image: "{{ .Values.image }}"
+{{- end }}
+`)},
+	"chart/.base/cronjob.yaml": {Data: []byte(`{{- if eq .Values.kindType "CronJob" }}
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+    name: "{{ .Values.name }}-{{ .Values.operationType }}"
+spec:
+    schedule: "{{ .Values.schedule }}"
+{{- end }}
+`)},
+}
+
+// TestResolveTemplate_ClusterBackupProcedureTemplate is checking if the script is taken from ClusterBackupProcedureTemplate
+func TestResolveTemplate_ClusterBackupProcedureTemplate(t *testing.T) {
 	tpl := v1alpha1.ClusterBackupProcedureTemplate{
 		TypeMeta:   v1.TypeMeta{},
 		ObjectMeta: v1.ObjectMeta{},
@@ -31,35 +81,213 @@ func TestWriteTemplate_ClusterBackupProcedureTemplate(t *testing.T) {
 	//
 	// ASSERT: operation == backup
 	//
-	writeErr := writeTemplate(logrus.WithContext(context.TODO()), &tpl, domain.Backup, dir+"/backup/redis.sh")
-	assert.Nil(t, writeErr)
-	bc, _ := os.ReadFile(dir + "/backup/redis.sh")
-	assert.Equal(t, string(bc), "#!/bin/bash\necho 'Hello backup'")
+	tplDef, script, err := resolveTemplate(testResources, &tpl, domain.Backup)
+	assert.Nil(t, err)
+	assert.Equal(t, "#!/bin/bash\necho 'Hello backup'", script)
+	assert.Equal(t, "putin:is-a-dickbag", tplDef.DefaultImage)
 
 	//
 	// ASSERT operation == restore
 	//
-	writeErr = writeTemplate(logrus.WithContext(context.TODO()), &tpl, domain.Restore, dir+"/restore/redis.sh")
-	assert.Nil(t, writeErr)
-	bc, _ = os.ReadFile(dir + "/restore/redis.sh")
-	assert.Equal(t, string(bc), "#!/bin/bash\necho 'Hello restore'")
+	_, script, err = resolveTemplate(testResources, &tpl, domain.Restore)
+	assert.Nil(t, err)
+	assert.Equal(t, "#!/bin/bash\necho 'Hello restore'", script)
+}
+
+// TestResolveTemplate_BackupProcedureTemplate is checking if the script is taken from namespaced BackupProcedureTemplate
+func TestResolveTemplate_BackupProcedureTemplate(t *testing.T) {
+	tpl := v1alpha1.BackupProcedureTemplate{
+		ObjectMeta: v1.ObjectMeta{Name: "redis", Namespace: "team-a"},
+		Spec: v1alpha1.BackupProcedureTemplateSpec{
+			Image:   "redis:7",
+			Backup:  "#!/bin/bash\necho 'Hello namespaced backup'",
+			Restore: "#!/bin/bash\necho 'Hello namespaced restore'",
+		},
+	}
+
+	_, script, err := resolveTemplate(testResources, &tpl, domain.Backup)
+	assert.Nil(t, err)
+	assert.Equal(t, "#!/bin/bash\necho 'Hello namespaced backup'", script)
+}
+
+// TestResolveTemplate_InternalTemplate is checking that the InternalTemplate is read from Backup Maker Generator resources
+func TestResolveTemplate_InternalTemplate(t *testing.T) {
+	tplDef, script, err := resolveTemplate(testResources, &domain.InternalTemplate{Name: "pg14"}, domain.Restore)
+	assert.Nil(t, err)
+	assert.Equal(t, "psql -h {{ .Params.hostname }}", script)
+	assert.Equal(t, "ghcr.io/riotkit-org/pgbr:pg14", tplDef.DefaultImage)
+
+	_, _, err = resolveTemplate(testResources, &domain.InternalTemplate{Name: "sqs"}, domain.Backup)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot find 'definition/sqs.json'")
+}
+
+// TestRenderKubernetesResourcesForOperation_InMemory is checking that rendering does not depend on working directory nor $HOME
+func TestRenderKubernetesResourcesForOperation_InMemory(t *testing.T) {
+	t.Setenv("HOME", "/non-existing")
+
+	aggregate := &domain.ScheduledBackupAggregate{
+		ScheduledBackup: &v1alpha1.ScheduledBackup{
+			ObjectMeta: v1.ObjectMeta{Name: "app1", Namespace: "team-a"},
+			Spec: v1alpha1.ScheduledBackupSpec{
+				Operation:       "backup",
+				Vars:            "Params:\n    hostname: postgres.team-a.svc\n",
+				GPGKeySecretRef: v1alpha1.GPGKeySecretSpec{PublicKey: "public", PrivateKey: "private"},
+			},
+		},
+		Template:           domain.InternalTemplate{Name: "pg14"},
+		GPGSecret:          &corev1.Secret{Data: map[string][]byte{"public": []byte("PUBLIC"), "private": []byte("PRIVATE")}},
+		AdditionalVarsList: domain.AdditionalVarsList{},
+	}
+
+	objects, err := RenderKubernetesResourcesForOperation(logrus.WithContext(context.TODO()), testResources, aggregate,
+		domain.Restore, domain.NewResourceTypesFilterForRequestedBackupAction(testRunnableKinds))
+
+	assert.Nil(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "app1-restore", objects[0].GetName())
+
+	containers, _, _ := unstructured.NestedSlice(objects[0].Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, "ghcr.io/riotkit-org/pgbr:pg14", containers[0].(map[string]interface{})["image"])
+	assert.Equal(t, "PRIVATE", string(aggregate.AdditionalVarsList["HelmValues.gpgKeyContent"]))
+}
+
+// TestRenderKubernetesResourcesFor_CronJobPerSchedule is checking that each schedule gets its own CronJob, also a restore
+func TestRenderKubernetesResourcesFor_CronJobPerSchedule(t *testing.T) {
+	aggregate := &domain.ScheduledBackupAggregate{
+		ScheduledBackup: &v1alpha1.ScheduledBackup{
+			ObjectMeta: v1.ObjectMeta{Name: "app1", Namespace: "team-a"},
+			Spec: v1alpha1.ScheduledBackupSpec{
+				Operation: "backup",
+				CronJob:   v1alpha1.CronJobSpec{Enabled: true, ScheduleEvery: "00 02 * * *"},
+				Schedules: []v1alpha1.ScheduleSpec{
+					{Name: "verify", Operation: "restore", ScheduleEvery: "00 04 * * 0"},
+				},
+				Vars:            "Params:\n    hostname: postgres.team-a.svc\n",
+				GPGKeySecretRef: v1alpha1.GPGKeySecretSpec{PublicKey: "public", PrivateKey: "private"},
+			},
+		},
+		Template:           domain.InternalTemplate{Name: "pg14"},
+		GPGSecret:          &corev1.Secret{Data: map[string][]byte{"public": []byte("PUBLIC"), "private": []byte("PRIVATE")}},
+		AdditionalVarsList: domain.AdditionalVarsList{},
+	}
+
+	objects, err := RenderKubernetesResourcesFor(logrus.WithContext(context.TODO()), testResources, aggregate)
+	assert.Nil(t, err)
+
+	schedules := make(map[string]string)
+	var configMaps []string
+	for _, obj := range objects {
+		switch obj.GetKind() {
+		case "CronJob":
+			schedules[obj.GetName()], _, _ = unstructured.NestedString(obj.Object, "spec", "schedule")
+		case "ConfigMap":
+			configMaps = append(configMaps, obj.GetName())
+		}
+	}
+	assert.Equal(t, map[string]string{"app1-backup": "00 02 * * *", "app1-restore-verify": "00 04 * * 0"}, schedules)
+	assert.Equal(t, []string{"app1-backup", "app1-restore"}, configMaps, "Expected that helper objects are rendered once per operation")
+
+	//
+	// ASSERT: .spec.cronJob disabled, only .spec.schedules are rendered
+	//
+	aggregate.Spec.CronJob.Enabled = false
+	objects, err = RenderKubernetesResourcesFor(logrus.WithContext(context.TODO()), testResources, aggregate)
+	assert.Nil(t, err)
+	assert.Equal(t, "app1-restore-verify", objects[len(objects)-1].GetName())
+	assert.Len(t, objects, 3)
+}
+
+// TestRenderKubernetesResourcesFor_RestoreTarget is checking that a restore into another namespace renders its own helpers,
+// named after the RequestedBackupAction, with vars overridden by .spec.restoreTarget.vars
+func TestRenderKubernetesResourcesFor_RestoreTarget(t *testing.T) {
+	scheduled := &domain.ScheduledBackupAggregate{
+		ScheduledBackup: &v1alpha1.ScheduledBackup{
+			ObjectMeta: v1.ObjectMeta{Name: "app1", Namespace: "production"},
+			Spec: v1alpha1.ScheduledBackupSpec{
+				Operation:       "backup",
+				Vars:            "Params:\n    hostname: postgres.production.svc\n",
+				GPGKeySecretRef: v1alpha1.GPGKeySecretSpec{PublicKey: "public", PrivateKey: "private"},
+			},
+		},
+		Template:           domain.InternalTemplate{Name: "pg14"},
+		GPGSecret:          &corev1.Secret{Data: map[string][]byte{"public": []byte("PUBLIC"), "private": []byte("PRIVATE")}},
+		AdditionalVarsList: domain.AdditionalVarsList{},
+		VarsOverrides:      "Params:\n    hostname: postgres.staging.svc\n",
+	}
+	action := domain.NewRequestedBackupActionAggregate(&v1alpha1.RequestedBackupAction{
+		ObjectMeta: v1.ObjectMeta{Name: "clone-to-staging", Namespace: "production"},
+		Spec: v1alpha1.RequestedBackupActionSpec{
+			Action:        "restore",
+			KindType:      "Job",
+			RestoreTarget: &v1alpha1.RestoreTargetSpec{Namespace: "staging"},
+		},
+	}, scheduled)
+	action.RunnableKinds = testRunnableKinds
+
+	objects, err := RenderKubernetesResourcesFor(logrus.WithContext(context.TODO()), testResources, action)
+	assert.Nil(t, err)
+	assert.Len(t, objects, 2)
+
+	assert.Equal(t, "staging", action.GetTargetNamespace())
+	for _, obj := range objects {
+		assert.Equal(t, "clone-to-staging-restore", obj.GetName())
+		if obj.GetKind() == "ConfigMap" {
+			script, _, _ := unstructured.NestedString(obj.Object, "data", "clone-to-staging")
+			assert.Contains(t, script, "psql -h postgres.staging.svc")
+		}
+	}
 }
 
-// TestWriteTemplate_InternalTemplate is checking that NO ANY FILE is written, when the input template is of InternalTemplate type
-func TestWriteTemplate_InternalTemplate(t *testing.T) {
-	dir, err := os.MkdirTemp("/tmp", "br-fdi")
-	if err != nil {
-		logrus.Fatal(err)
+// TestMergeVars is checking that nested maps are merged, and other values are replaced
+func TestMergeVars(t *testing.T) {
+	vars := map[string]interface{}{
+		"Params":     map[string]interface{}{"hostname": "postgres.production.svc", "port": 5432},
+		"Repository": map[string]interface{}{"collection": "app1"},
+		"Image":      "pg14",
+	}
+	overrides := map[string]interface{}{
+		"Params": map[string]interface{}{"hostname": "postgres.staging.svc"},
+		"Image":  map[string]interface{}{"tag": "custom"},
 	}
 
-	tpl := domain.InternalTemplate{Name: "sqs"}
-	writeErr := writeTemplate(logrus.WithContext(context.TODO()), &tpl, domain.Backup, dir+"/backup/sqs.sh")
-	assert.Nil(t, writeErr)
+	assert.Equal(t, map[string]interface{}{
+		"Params":     map[string]interface{}{"hostname": "postgres.staging.svc", "port": 5432},
+		"Repository": map[string]interface{}{"collection": "app1"},
+		"Image":      map[string]interface{}{"tag": "custom"},
+	}, MergeVars(vars, overrides))
+}
+
+// TestApplyTimeout is checking that the timeout is converted into activeDeadlineSeconds of Jobs, also those spawned by CronJob
+func TestApplyTimeout(t *testing.T) {
+	objects := []unstructured.Unstructured{
+		{Object: map[string]interface{}{"apiVersion": "batch/v1", "kind": "Job", "metadata": map[string]interface{}{"name": "app1-backup"}}},
+		{Object: map[string]interface{}{"apiVersion": "batch/v1", "kind": "CronJob", "metadata": map[string]interface{}{"name": "app1-backup"}}},
+		{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "app1-backup"}}},
+		{Object: map[string]interface{}{"apiVersion": "tekton.dev/v1", "kind": "PipelineRun", "metadata": map[string]interface{}{"name": "app1-backup"}}},
+	}
+
+	assert.Nil(t, applyTimeout(objects, time.Minute*90+time.Millisecond))
 
-	// The file should not exist
-	_, existsErr := os.Stat(dir + "/backup/sqs.sh")
-	assert.NotNil(t, existsErr)
-	assert.Contains(t, existsErr.Error(), "no such file or directory")
+	jobDeadline, _, _ := unstructured.NestedInt64(objects[0].Object, "spec", "activeDeadlineSeconds")
+	assert.Equal(t, int64(5401), jobDeadline)
+	cronJobDeadline, _, _ := unstructured.NestedInt64(objects[1].Object, "spec", "jobTemplate", "spec", "activeDeadlineSeconds")
+	assert.Equal(t, int64(5401), cronJobDeadline)
+	_, hasSpec := objects[2].Object["spec"]
+	assert.False(t, hasSpec)
+	pipelineTimeout, _, _ := unstructured.NestedString(objects[3].Object, "spec", "timeouts", "pipeline")
+	assert.Equal(t, "5401s", pipelineTimeout)
+}
+
+// TestRenderScript is checking that the script is rendered with the same functions as in Backup Maker Generator
+func TestRenderScript(t *testing.T) {
+	cfg := generate.NewComputedConfiguration(generate.TemplateDefinition{}, generate.Config{
+		Params: map[string]interface{}{"hostname": " postgres ", "databases": []string{"app"}},
+	})
+
+	rendered, err := renderScript("test", `pg_dump -h {{ .Params.hostname | trim }} {{ toJson .Params.databases }}`, cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, `pg_dump -h postgres ["app"]`, rendered)
 }
 
 func TestParseRenderedManifests_FiltersOutKinds_RBACase(t *testing.T) {
@@ -80,7 +308,7 @@ metadata:
 `
 
 	objects, err := parseRenderedManifests(logrus.WithContext(context.TODO()), content,
-		domain.NewResourceTypesFilterForRequestedBackupAction())
+		domain.NewResourceTypesFilterForRequestedBackupAction(testRunnableKinds))
 
 	assert.Len(t, objects, 1)
 	assert.Equal(t, "Job", objects[0].GroupVersionKind().Kind)
@@ -117,20 +345,7 @@ metadata:
 `
 
 	objects, err := parseRenderedManifests(logrus.WithContext(context.TODO()), content,
-		domain.NewResourceTypesFilterForScheduledBackup(
-			&domain.ScheduledBackupAggregate{
-				ScheduledBackup: &v1alpha1.ScheduledBackup{
-					Spec: v1alpha1.ScheduledBackupSpec{
-						Operation: "backup",
-						CronJob: v1alpha1.CronJobSpec{
-							Enabled:       false,
-							ScheduleEvery: "",
-						},
-					},
-				},
-			},
-			domain.Backup,
-		))
+		domain.NewResourceTypesFilterForScheduledBackup())
 
 	assert.Len(t, objects, 2)
 	assert.Equal(t, "ConfigMap", objects[0].GroupVersionKind().Kind)
@@ -138,7 +353,7 @@ metadata:
 	assert.Nil(t, err)
 }
 
-func TestParseRenderedManifests_FiltersOutKinds_SBCase_WithCronJob(t *testing.T) {
+func TestParseRenderedManifests_FiltersOutKinds_ScheduleCase(t *testing.T) {
 	content := `---
 apiVersion: v1
 kind: ConfigMap
@@ -168,24 +383,126 @@ metadata:
 `
 
 	objects, err := parseRenderedManifests(logrus.WithContext(context.TODO()), content,
-		domain.NewResourceTypesFilterForScheduledBackup(
-			&domain.ScheduledBackupAggregate{
-				ScheduledBackup: &v1alpha1.ScheduledBackup{
-					Spec: v1alpha1.ScheduledBackupSpec{
-						Operation: "backup",
-						CronJob: v1alpha1.CronJobSpec{
-							Enabled:       true, // NOTICE HERE: CronJob is enabled
-							ScheduleEvery: "*/3 * * * *",
-						},
-					},
-				},
-			},
-			domain.Backup,
-		))
+		domain.NewResourceTypesFilterForSchedule())
 
-	assert.Len(t, objects, 3)
-	assert.Equal(t, "ConfigMap", objects[0].GroupVersionKind().Kind)
-	assert.Equal(t, "Secret", objects[1].GroupVersionKind().Kind)
-	assert.Equal(t, "CronJob", objects[2].GroupVersionKind().Kind)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "CronJob", objects[0].GroupVersionKind().Kind)
+	assert.Nil(t, err)
+}
+
+// testPodSpecResources are Backup Maker Generator's chart helpers, that are used by the templates bundled with the controller
+var testPodSpecResources = fstest.MapFS{
+	"chart/.base/_helpers.tpl": {Data: []byte(`{{- define "resourceName" -}}{{ .Values.name }}-{{ .Values.operationType }}{{ end }}
+{{- define "podSpecTemplate" -}}
+serviceAccountName: default
+restartPolicy: Never
+initContainers:
+    - name: injector
+      image: {{ .Values.backupMakerImage }}
+containers:
+    - name: backup-maker
+      image: {{ .Values.image }}
+volumes:
+    - name: init-scripts
+      emptyDir: {}
+{{- end }}`)},
+}
+
+// renderBundledKind is rendering a single runnable object of given kindType using the templates bundled with the controller
+func renderBundledKind(t *testing.T, kindType string) unstructured.Unstructured {
+	files, err := loadChartFiles(testPodSpecResources)
 	assert.Nil(t, err)
+
+	cfg := generate.NewComputedConfiguration(generate.TemplateDefinition{}, generate.Config{
+		HelmValues: map[string]interface{}{"kindType": kindType},
+	})
+	content, err := renderChart(files, chartParams{JobName: "app1", Image: "ghcr.io/riotkit-org/pgbr:pg14", Operation: domain.Restore}, cfg)
+	assert.Nil(t, err)
+
+	objects, err := parseRenderedManifests(logrus.WithContext(context.TODO()), content, domain.NewResourceTypesFilterForRequestedBackupAction(testRunnableKinds))
+	assert.Nil(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, kindType, objects[0].GetKind())
+	assert.Equal(t, "app1-restore-", objects[0].GetGenerateName())
+	return objects[0]
+}
+
+// TestRenderChart_BundledWorkflow is checking that an Argo Workflow reuses the Pod spec of Backup Maker Generator's chart
+func TestRenderChart_BundledWorkflow(t *testing.T) {
+	workflow := renderBundledKind(t, "Workflow")
+
+	templates, _, _ := unstructured.NestedSlice(workflow.Object, "spec", "templates")
+	assert.Equal(t, "ghcr.io/riotkit-org/pgbr:pg14", templates[0].(map[string]interface{})["container"].(map[string]interface{})["image"])
+	volumes, _, _ := unstructured.NestedSlice(workflow.Object, "spec", "volumes")
+	assert.Len(t, volumes, 1)
+	deadline, _, _ := unstructured.NestedInt64(workflow.Object, "spec", "activeDeadlineSeconds")
+	assert.Equal(t, int64(3600), deadline)
+}
+
+// TestRenderChart_BundledTekton is checking that init containers of the Pod spec are becoming first steps of a Tekton Task
+func TestRenderChart_BundledTekton(t *testing.T) {
+	taskRun := renderBundledKind(t, "TaskRun")
+	steps, _, _ := unstructured.NestedSlice(taskRun.Object, "spec", "taskSpec", "steps")
+	assert.Len(t, steps, 2)
+	assert.Equal(t, "injector", steps[0].(map[string]interface{})["name"])
+	assert.Equal(t, "backup-maker", steps[1].(map[string]interface{})["name"])
+	timeout, _, _ := unstructured.NestedString(taskRun.Object, "spec", "timeout")
+	assert.Equal(t, "3600s", timeout)
+	_, hasPodTemplate := taskRun.Object["spec"].(map[string]interface{})["podTemplate"]
+	assert.False(t, hasPodTemplate, "Expected no empty podTemplate, when there is no nodeSelector, affinity nor tolerations")
+
+	pipelineRun := renderBundledKind(t, "PipelineRun")
+	tasks, _, _ := unstructured.NestedSlice(pipelineRun.Object, "spec", "pipelineSpec", "tasks")
+	steps, _, _ = unstructured.NestedSlice(tasks[0].(map[string]interface{}), "taskSpec", "steps")
+	assert.Len(t, steps, 2)
+	serviceAccount, _, _ := unstructured.NestedString(pipelineRun.Object, "spec", "taskRunTemplate", "serviceAccountName")
+	assert.Equal(t, "default", serviceAccount)
+}
+
+func TestAppendExtraEnv(t *testing.T) {
+	userEnv := []interface{}{
+		map[string]interface{}{"name": "TZ", "value": "Europe/Warsaw"},
+		map[string]interface{}{"name": "RESTORE_VERSION", "value": "latest"},
+	}
+
+	merged := appendExtraEnv(userEnv, map[string]string{"RESTORE_VERSION": "v3"})
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "TZ", "value": "Europe/Warsaw"},
+		map[string]interface{}{"name": "RESTORE_VERSION", "value": "v3"},
+	}, merged)
+	assert.Len(t, userEnv, 2, "Expected that .HelmValues.extraEnv is not modified, it is shared by all renders")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "RESTORE_VERSION", "value": "v3"}}, appendExtraEnv(nil, map[string]string{"RESTORE_VERSION": "v3"}))
+}
+
+// TestExtractResources_KeepsEnvironment is checking that extracting Backup Maker Generator resources does not leave $BM_HOME changed
+func TestExtractResources_KeepsEnvironment(t *testing.T) {
+	t.Setenv("BM_HOME", "/previous")
+
+	resources, err := ExtractResources(t.TempDir())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/previous", os.Getenv("BM_HOME"))
+	_, readErr := readResource(resources, "chart", "secret.yaml")
+	assert.Nil(t, readErr)
+}
+
+// TestRenderChart_SealedSecretGPGKey is checking that a GPG key stored as a SealedSecret is detected the same way as Backup Maker Generator does
+func TestRenderChart_SealedSecretGPGKey(t *testing.T) {
+	resources, err := ExtractResources(t.TempDir())
+	assert.Nil(t, err)
+	files, err := loadChartFiles(resources)
+	assert.Nil(t, err)
+	cfg := generate.NewComputedConfiguration(generate.TemplateDefinition{}, generate.Config{})
+	sealed := "apiVersion: bitnami.com/v1alpha1\nkind: SealedSecret\nmetadata:\n    name: backup-gpg\n    namespace: team-a\nspec:\n    encryptedData:\n        gpg-key: AgBy3i4OJSWK\n"
+
+	content, err := renderChart(files, chartParams{JobName: "app1", Namespace: "team-a", GPGKey: sealed, Operation: domain.Backup}, cfg)
+
+	assert.Nil(t, err)
+	assert.Contains(t, content, "kind: SealedSecret")
+	assert.Contains(t, content, "secretName: backup-gpg")
+	assert.NotContains(t, content, "gpg-key: \"YXBpVmVyc2lvbj", "Expected that the SealedSecret is not placed as a plain key in the Secret")
+
+	_, err = renderChart(files, chartParams{JobName: "app1", Namespace: "team-b", GPGKey: sealed, Operation: domain.Backup}, cfg)
+	assert.ErrorContains(t, err, "SealedSecret is invalid: it is in different Namespace (team-a), expected to be in 'team-b'")
 }
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	memory "k8s.io/client-go/discovery/cached"
 	"k8s.io/client-go/dynamic"
@@ -16,53 +18,103 @@ import (
 	"k8s.io/client-go/tools/record"
 )
 
+// FieldManager is the name under which the controller owns fields of applied objects (Server-Side Apply)
+const FieldManager = "backup-maker-controller"
+
+// ConflictPolicy decides what happens, when applied fields are already owned by other field manager - e.g. other controller or a human using kubectl
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyForce takes over the ownership of conflicting fields, each conflict is reported as a Warning event
+	ConflictPolicyForce ConflictPolicy = "force"
+
+	// ConflictPolicyFail leaves the object untouched, reports a Warning event and fails the reconciliation
+	ConflictPolicyFail ConflictPolicy = "fail"
+)
+
+// ParseConflictPolicy is converting a string e.g. from commandline into a ConflictPolicy
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	policy := ConflictPolicy(name)
+	if policy != ConflictPolicyForce && policy != ConflictPolicyFail {
+		return "", errors.Errorf("unknown conflict policy '%s', expected one of: %s, %s", name, ConflictPolicyForce, ConflictPolicyFail)
+	}
+	return policy, nil
+}
+
 func CreateOrUpdate(
 	ctx context.Context,
 	recorder record.EventRecorder,
 	dyn dynamic.Interface,
-	restCfg *rest.Config,
+	mapper meta.ResettableRESTMapper,
 	obj *unstructured.Unstructured,
 	backup runtime.Object, // todo: change to runtime.Object
+	conflictPolicy ConflictPolicy,
 ) error {
-	dc, err := discovery.NewDiscoveryClientForConfig(restCfg)
+	c, err := getResourceClient(mapper, dyn, obj.GroupVersionKind(), obj.GetNamespace())
 	if err != nil {
-		return errors.Wrap(err, "cannot create Discovery Client for checking installed api resources")
+		return err
 	}
 
-	// construct dynamic client mapped to proper ApiVersion, Group, Kind
-	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
-	mapping, err := mapper.RESTMapping(obj.GroupVersionKind().GroupKind(), obj.GroupVersionKind().Version)
-	c := dyn.Resource(mapping.Resource).Namespace(obj.GetNamespace())
-
 	apiVersion, kind := obj.GroupVersionKind().ToAPIVersionAndKind()
 
 	// resources like Job or Pod will be created again every time
 	if obj.GetName() == "" && obj.GetGenerateName() != "" {
-		if _, createErr := c.Create(ctx, obj, v1.CreateOptions{}); createErr != nil {
+		created, createErr := c.Create(ctx, obj, v1.CreateOptions{FieldManager: FieldManager})
+		if createErr != nil {
 			return errors.Wrap(createErr, "cannot create object in API")
 		}
-		recorder.Event(backup, "Normal", "Updated", fmt.Sprintf("Creating %s/%s, named %s/%s", apiVersion, kind, obj.GetNamespace(), obj.GetName()))
+		// the name is known only after creation, keep it, so the parent could refer to the object
+		obj.SetName(created.GetName())
+		recorder.Event(backup, "Normal", "Created", fmt.Sprintf("Creating %s/%s, named %s/%s", apiVersion, kind, created.GetNamespace(), created.GetName()))
 		return nil
 	}
 
-	// try to fetch resource
-	if _, getErr := c.Get(ctx, obj.GetName(), v1.GetOptions{}); getErr != nil {
-		if !apierrors.IsNotFound(getErr) {
-			return errors.Wrap(getErr, "cannot fetch object information from API server to check if it exists")
-		}
+	if applyErr := serverSideApply(ctx, recorder, c, obj, backup, conflictPolicy); applyErr != nil {
+		return errors.Wrap(applyErr, "cannot apply object in API")
+	}
+	recorder.Event(backup, "Normal", "Applied", fmt.Sprintf("Applying %s/%s, named %s/%s", apiVersion, kind, obj.GetNamespace(), obj.GetName()))
+	return nil
+}
 
-		// if not found, then CREATE
-		if _, createErr := c.Create(ctx, obj, v1.CreateOptions{}); createErr != nil {
-			return errors.Wrap(createErr, "cannot create object in API")
-		}
-		recorder.Event(backup, "Normal", "Created", fmt.Sprintf("Creating %s/%s, named %s/%s", apiVersion, kind, obj.GetNamespace(), obj.GetName()))
-		return nil
+// serverSideApply is applying the object using Server-Side Apply, so the fields set by other controllers or humans are kept.
+// Conflicts on fields that were set by others are handled according to the ConflictPolicy
+func serverSideApply(ctx context.Context, recorder record.EventRecorder, c dynamic.ResourceInterface, obj *unstructured.Unstructured, backup runtime.Object, conflictPolicy ConflictPolicy) error {
+	_, err := c.Apply(ctx, obj.GetName(), obj, v1.ApplyOptions{FieldManager: FieldManager})
+	if err == nil || !apierrors.IsConflict(err) {
+		return err
 	}
 
-	// else - if found, UPDATE
-	if _, updateErr := c.Update(ctx, obj, v1.UpdateOptions{}); updateErr != nil {
-		return errors.Wrap(updateErr, "cannot update object in API")
+	apiVersion, kind := obj.GroupVersionKind().ToAPIVersionAndKind()
+	if conflictPolicy != ConflictPolicyForce {
+		recorder.Event(backup, "Warning", "ApplyConflict", fmt.Sprintf("Not applying %s/%s, named %s/%s - fields are managed by someone else: %s", apiVersion, kind, obj.GetNamespace(), obj.GetName(), err.Error()))
+		return err
 	}
-	recorder.Event(backup, "Normal", "Updated", fmt.Sprintf("Updating %s/%s, named %s/%s", apiVersion, kind, obj.GetNamespace(), obj.GetName()))
-	return nil
+
+	recorder.Event(backup, "Warning", "ApplyConflict", fmt.Sprintf("Taking over fields of %s/%s, named %s/%s: %s", apiVersion, kind, obj.GetNamespace(), obj.GetName(), err.Error()))
+	_, err = c.Apply(ctx, obj.GetName(), obj, v1.ApplyOptions{FieldManager: FieldManager, Force: true})
+	return err
+}
+
+// NewRESTMapper is creating a long-living RESTMapper that caches the discovery information between reconciliations
+func NewRESTMapper(restCfg *rest.Config) (meta.ResettableRESTMapper, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(restCfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create Discovery Client for checking installed api resources")
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc)), nil
+}
+
+// getResourceClient is constructing a dynamic client mapped to proper ApiVersion, Group, Kind
+func getResourceClient(mapper meta.ResettableRESTMapper, dyn dynamic.Interface, gvk schema.GroupVersionKind, namespace string) (dynamic.ResourceInterface, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+
+	// the cached discovery information could be outdated e.g. a CRD was installed after the controller has started
+	if meta.IsNoMatchError(err) {
+		mapper.Reset()
+		mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot find API resource for %s", gvk.String())
+	}
+	return dyn.Resource(mapping.Resource).Namespace(namespace), nil
 }
@@ -0,0 +1,111 @@
+package bmg
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+	"testing"
+)
+
+// conflictingResource is a stub for the API that rejects every not forced apply with a conflict
+type conflictingResource struct {
+	dynamic.ResourceInterface
+	applies []v1.ApplyOptions
+}
+
+func (cr *conflictingResource) Apply(ctx context.Context, name string, obj *unstructured.Unstructured, options v1.ApplyOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	cr.applies = append(cr.applies, options)
+	if !options.Force {
+		return nil, apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, name, nil)
+	}
+	return obj, nil
+}
+
+func createAppliedConfigMap() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName("app1-backup")
+	obj.SetNamespace("default")
+	return obj
+}
+
+func TestServerSideApply_ForcePolicyTakesOverFieldsAndReportsConflict(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	resource := &conflictingResource{}
+
+	err := serverSideApply(context.TODO(), recorder, resource, createAppliedConfigMap(), createAppliedConfigMap(), ConflictPolicyForce)
+
+	assert.Nil(t, err)
+	assert.Len(t, resource.applies, 2)
+	assert.Equal(t, FieldManager, resource.applies[0].FieldManager)
+	assert.False(t, resource.applies[0].Force)
+	assert.True(t, resource.applies[1].Force)
+	assert.Contains(t, <-recorder.Events, "Warning ApplyConflict Taking over fields of v1/ConfigMap, named default/app1-backup")
+}
+
+func TestServerSideApply_FailPolicyDoesNotForce(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	resource := &conflictingResource{}
+
+	err := serverSideApply(context.TODO(), recorder, resource, createAppliedConfigMap(), createAppliedConfigMap(), ConflictPolicyFail)
+
+	assert.True(t, apierrors.IsConflict(err))
+	assert.Len(t, resource.applies, 1)
+	assert.Contains(t, <-recorder.Events, "Warning ApplyConflict Not applying v1/ConfigMap, named default/app1-backup")
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	policy, err := ParseConflictPolicy("fail")
+	assert.Nil(t, err)
+	assert.Equal(t, ConflictPolicyFail, policy)
+
+	_, err = ParseConflictPolicy("ignore")
+	assert.NotNil(t, err)
+}
+
+// outdatedMapper is a stub for the cached RESTMapper that does not know any kind until it is reset
+type outdatedMapper struct {
+	*meta.DefaultRESTMapper
+	resets int
+}
+
+func (om *outdatedMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	if om.resets == 0 {
+		return nil, &meta.NoKindMatchError{GroupKind: gk, SearchedVersions: versions}
+	}
+	return om.DefaultRESTMapper.RESTMapping(gk, versions...)
+}
+
+func (om *outdatedMapper) Reset() {
+	om.resets += 1
+}
+
+func TestGetResourceClient_ResetsOutdatedMapperOnce(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Workflow"}
+	mapper := &outdatedMapper{DefaultRESTMapper: meta.NewDefaultRESTMapper([]schema.GroupVersion{gvk.GroupVersion()})}
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+
+	c, err := getResourceClient(mapper, dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), gvk, "default")
+	assert.Nil(t, err)
+	assert.NotNil(t, c)
+	assert.Equal(t, 1, mapper.resets)
+}
+
+func TestGetResourceClient_UnknownKindIsReportedAfterReset(t *testing.T) {
+	mapper := &outdatedMapper{DefaultRESTMapper: meta.NewDefaultRESTMapper([]schema.GroupVersion{})}
+	gvk := schema.GroupVersionKind{Group: "tekton.dev", Version: "v1", Kind: "PipelineRun"}
+
+	_, err := getResourceClient(mapper, dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), gvk, "default")
+	assert.NotNil(t, err)
+	assert.True(t, meta.IsNoMatchError(err))
+	assert.Equal(t, 1, mapper.resets)
+}
@@ -12,10 +12,18 @@ type FakeRiotkitV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeRiotkitV1alpha1) BackupProcedureTemplates(namespace string) v1alpha1.BackupProcedureTemplateInterface {
+	return &FakeBackupProcedureTemplates{c, namespace}
+}
+
 func (c *FakeRiotkitV1alpha1) ClusterBackupProcedureTemplates(namespace string) v1alpha1.ClusterBackupProcedureTemplateInterface {
 	return &FakeClusterBackupProcedureTemplates{c, namespace}
 }
 
+func (c *FakeRiotkitV1alpha1) NotificationChannels(namespace string) v1alpha1.NotificationChannelInterface {
+	return &FakeNotificationChannels{c, namespace}
+}
+
 func (c *FakeRiotkitV1alpha1) RequestedBackupActions(namespace string) v1alpha1.RequestedBackupActionInterface {
 	return &FakeRequestedBackupActions{c, namespace}
 }
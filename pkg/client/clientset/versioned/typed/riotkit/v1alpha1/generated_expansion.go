@@ -2,8 +2,12 @@
 
 package v1alpha1
 
+type BackupProcedureTemplateExpansion interface{}
+
 type ClusterBackupProcedureTemplateExpansion interface{}
 
+type NotificationChannelExpansion interface{}
+
 type RequestedBackupActionExpansion interface{}
 
 type ScheduledBackupExpansion interface{}
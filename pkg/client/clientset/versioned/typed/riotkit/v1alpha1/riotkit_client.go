@@ -10,7 +10,9 @@ import (
 
 type RiotkitV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	BackupProcedureTemplatesGetter
 	ClusterBackupProcedureTemplatesGetter
+	NotificationChannelsGetter
 	RequestedBackupActionsGetter
 	ScheduledBackupsGetter
 }
@@ -20,10 +22,18 @@ type RiotkitV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *RiotkitV1alpha1Client) BackupProcedureTemplates(namespace string) BackupProcedureTemplateInterface {
+	return newBackupProcedureTemplates(c, namespace)
+}
+
 func (c *RiotkitV1alpha1Client) ClusterBackupProcedureTemplates(namespace string) ClusterBackupProcedureTemplateInterface {
 	return newClusterBackupProcedureTemplates(c, namespace)
 }
 
+func (c *RiotkitV1alpha1Client) NotificationChannels(namespace string) NotificationChannelInterface {
+	return newNotificationChannels(c, namespace)
+}
+
 func (c *RiotkitV1alpha1Client) RequestedBackupActions(namespace string) RequestedBackupActionInterface {
 	return newRequestedBackupActions(c, namespace)
 }
@@ -37,8 +37,12 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=riotkit.org, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("backupproceduretemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Riotkit().V1alpha1().BackupProcedureTemplates().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("clusterbackupproceduretemplates"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Riotkit().V1alpha1().ClusterBackupProcedureTemplates().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("notificationchannels"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Riotkit().V1alpha1().NotificationChannels().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("requestedbackupactions"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Riotkit().V1alpha1().RequestedBackupActions().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("scheduledbackups"):
@@ -8,8 +8,12 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// BackupProcedureTemplates returns a BackupProcedureTemplateInformer.
+	BackupProcedureTemplates() BackupProcedureTemplateInformer
 	// ClusterBackupProcedureTemplates returns a ClusterBackupProcedureTemplateInformer.
 	ClusterBackupProcedureTemplates() ClusterBackupProcedureTemplateInformer
+	// NotificationChannels returns a NotificationChannelInformer.
+	NotificationChannels() NotificationChannelInformer
 	// RequestedBackupActions returns a RequestedBackupActionInformer.
 	RequestedBackupActions() RequestedBackupActionInformer
 	// ScheduledBackups returns a ScheduledBackupInformer.
@@ -27,11 +31,21 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// BackupProcedureTemplates returns a BackupProcedureTemplateInformer.
+func (v *version) BackupProcedureTemplates() BackupProcedureTemplateInformer {
+	return &backupProcedureTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ClusterBackupProcedureTemplates returns a ClusterBackupProcedureTemplateInformer.
 func (v *version) ClusterBackupProcedureTemplates() ClusterBackupProcedureTemplateInformer {
 	return &clusterBackupProcedureTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// NotificationChannels returns a NotificationChannelInformer.
+func (v *version) NotificationChannels() NotificationChannelInformer {
+	return &notificationChannelInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // RequestedBackupActions returns a RequestedBackupActionInformer.
 func (v *version) RequestedBackupActions() RequestedBackupActionInformer {
 	return &requestedBackupActionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -2,6 +2,14 @@
 
 package v1alpha1
 
+// BackupProcedureTemplateListerExpansion allows custom methods to be added to
+// BackupProcedureTemplateLister.
+type BackupProcedureTemplateListerExpansion interface{}
+
+// BackupProcedureTemplateNamespaceListerExpansion allows custom methods to be added to
+// BackupProcedureTemplateNamespaceLister.
+type BackupProcedureTemplateNamespaceListerExpansion interface{}
+
 // ClusterBackupProcedureTemplateListerExpansion allows custom methods to be added to
 // ClusterBackupProcedureTemplateLister.
 type ClusterBackupProcedureTemplateListerExpansion interface{}
@@ -10,6 +18,14 @@ type ClusterBackupProcedureTemplateListerExpansion interface{}
 // ClusterBackupProcedureTemplateNamespaceLister.
 type ClusterBackupProcedureTemplateNamespaceListerExpansion interface{}
 
+// NotificationChannelListerExpansion allows custom methods to be added to
+// NotificationChannelLister.
+type NotificationChannelListerExpansion interface{}
+
+// NotificationChannelNamespaceListerExpansion allows custom methods to be added to
+// NotificationChannelNamespaceLister.
+type NotificationChannelNamespaceListerExpansion interface{}
+
 // RequestedBackupActionListerExpansion allows custom methods to be added to
 // RequestedBackupActionLister.
 type RequestedBackupActionListerExpansion interface{}
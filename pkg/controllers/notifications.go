@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"context"
+	riotkitorgv1alpha1 "github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/notification"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"strings"
+	"time"
+)
+
+// notificationTimeout is limiting the delivery to all channels, including retries
+const notificationTimeout = time.Minute * 5
+
+// sendNotification is delivering the notification in background, so a slow receiver is not blocking the reconciliation.
+// A failed delivery is reported as an event on the notifying object
+func sendNotification(sender *notification.Sender, recorder record.EventRecorder, logger *logrus.Entry, object runtime.Object,
+	refs []riotkitorgv1alpha1.NotificationChannelRefSpec, n notification.Notification) {
+
+	if sender == nil || len(refs) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+		defer cancel()
+		if err := sender.Send(ctx, logger, n.Namespace, refs, n); err != nil {
+			logger.Error(err)
+			recorder.Event(object, "Warning", "NotificationFailed", err.Error())
+		}
+	}()
+}
+
+// describeFailures is collecting messages of failed objects from the health report
+func describeFailures(report []riotkitorgv1alpha1.JobHealthStatus) string {
+	var messages []string
+	for _, status := range report {
+		if !status.Failed {
+			continue
+		}
+		message := status.Message
+		if status.Failure != nil && status.Failure.Reason != "" {
+			message += " (" + status.Failure.Reason + ")"
+		}
+		messages = append(messages, message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func toTime(t *metav1.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return &t.Time
+}
@@ -16,7 +16,7 @@ func createOwnedReferencesHealthReport(ctx context.Context, ownedReferences riot
 	for _, resource := range ownedReferences {
 
 		// get a health status
-		status, err := integrations.GetScheduledJobHealthStatus(ctx, resource.GetGVK(), resource.TrackingId, namespace)
+		status, err := integrations.GetScheduledJobHealthStatus(ctx, resource.GetGVK(), resource.TrackingId, resource.GetNamespace(namespace))
 		logger.Debugf("JobStatus = %v, err = %v", status, err)
 		if err != nil {
 			// we have to ignore resources like Secrets, ConfigMaps etc.
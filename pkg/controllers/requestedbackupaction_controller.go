@@ -25,15 +25,20 @@ import (
 	"github.com/riotkit-org/backup-maker-controller/pkg/client/clientset/versioned/typed/riotkit/v1alpha1"
 	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
 	"github.com/riotkit-org/backup-maker-controller/pkg/factory"
+	"github.com/riotkit-org/backup-maker-controller/pkg/integration"
 	"github.com/riotkit-org/backup-maker-controller/pkg/locking"
+	"github.com/riotkit-org/backup-maker-controller/pkg/repository"
 	"github.com/sirupsen/logrus"
+	"io/fs"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"strings"
@@ -47,23 +52,42 @@ import (
 // RequestedBackupActionReconciler reconciles a RequestedBackupAction object
 type RequestedBackupActionReconciler struct {
 	client.Client
-	RestCfg   *rest.Config
-	DynClient dynamic.Interface
-	BRClient  v1alpha1.RiotkitV1alpha1Interface
-	Scheme    *runtime.Scheme
-	Cache     cache.Cache
-	Fetcher   factory.CachedFetcher
-	Recorder  record.EventRecorder
-	Locker    locking.Locker
+	RESTMapper meta.ResettableRESTMapper
+	DynClient  dynamic.Interface
+	BRClient   v1alpha1.RiotkitV1alpha1Interface
+	Scheme     *runtime.Scheme
+	Cache      cache.Cache
+	Fetcher    factory.CachedFetcher
+	Recorder   record.EventRecorder
+	Locker     locking.Locker
+
+	// Resources are templates and the Helm chart of Backup Maker Generator, used to render children objects
+	Resources fs.FS
+
+	// ConflictPolicy decides if fields owned by other field managers are taken over, when applying children objects
+	ConflictPolicy bmg.ConflictPolicy
+
+	// Integrations are adapters enabled with `--integrations` and served by the cluster, those decide which .spec.kindType can be used
+	Integrations *integration.AllSupportedJobResourceTypes
+
+	// RestoreTargetsValidatedByWebhook is true, when the validating webhook is checking permissions of the user in .spec.restoreTarget.namespace.
+	// Without the webhook, the target namespace has to allow restores with AnnotationAllowRestoreFrom
+	RestoreTargetsValidatedByWebhook bool
 }
 
 func (r *RequestedBackupActionReconciler) fetchAggregate(ctx context.Context, logger *logrus.Entry, req ctrl.Request) (*domain.RequestedBackupActionAggregate, ctrl.Result, error) {
-	return factory.FetchRBAAggregate(ctx, r.Fetcher, r.Client, logger, req)
+	aggregate, ctrlResult, err := factory.FetchRBAAggregate(ctx, r.Fetcher, r.Client, logger, req)
+	aggregate.RunnableKinds = r.Integrations.GetRunnableKinds()
+	return aggregate, ctrlResult, err
 }
 
 // +kubebuilder:rbac:groups=riotkit.org,resources=requestedbackupactions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=riotkit.org,resources=requestedbackupactions/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=riotkit.org,resources=requestedbackupactions/finalizers,verbs=update
+// +kubebuilder:rbac:groups=,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=argoproj.io,resources=workflows,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=tekton.dev,resources=taskruns,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile main loop for RequestedBackupAction controller
 func (r *RequestedBackupActionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -84,7 +108,14 @@ func (r *RequestedBackupActionReconciler) Reconcile(ctx context.Context, req ctr
 	defer r.Locker.Done(ctx, lock)
 
 	//
-	// 1. Fetch all required objects
+	// 1. Objects placed in other namespaces are not deleted by the garbage collector, those are deleted together with the action
+	//
+	if deleted, finalizeErr := r.finalize(ctx, logger, req); deleted || finalizeErr != nil {
+		return ctrl.Result{}, finalizeErr
+	}
+
+	//
+	// 2. Fetch all required objects
 	//
 	aggregate, ctrlResult, err := r.fetchAggregate(ctx, logger, req)
 	if err != nil {
@@ -100,10 +131,66 @@ func (r *RequestedBackupActionReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, nil
 	}
 
+	// the cache may be behind the API, and the same attempt must not spawn a second Job
+	fresh, getErr := r.BRClient.RequestedBackupActions(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
+	if getErr != nil {
+		return ctrl.Result{}, errors.Wrap(getErr, "cannot fetch RequestedBackupAction from API")
+	}
+	if fresh.ResourceVersion != aggregate.ResourceVersion {
+		logger.Debugf("Cache is not up-to-date yet, requeuing")
+		return ctrl.Result{RequeueAfter: time.Second * 2}, nil
+	}
+
+	// a retry of a failed attempt has to wait for the backoff
+	if delay := aggregate.GetRetryDelay(time.Now()); delay > 0 {
+		logger.Infof("Waiting %s before attempt %d", delay.Round(time.Second), aggregate.Status.Attempts+1)
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	// the webhook is rejecting not enabled kinds, but the action could be created before the integration was disabled
+	if !aggregate.IsKindTypeRunnable() {
+		r.rejectAction(ctx, logger, aggregate, metav1.Condition{Type: ConditionKindTypeEnabled, ObservedGeneration: aggregate.Generation}, "KindTypeNotEnabled",
+			fmt.Sprintf("No enabled integration can run .spec.kindType '%s', available: %v. Check the `--integrations` flag of the controller and if the CRD is installed",
+				aggregate.Spec.KindType, domain.RunnableKindTypeNames(aggregate.RunnableKinds)))
+		return ctrl.Result{}, nil
+	}
+
+	// the controller creates Pods and Secrets in the target namespace, on behalf of a user that could have no access there
+	if refusal, checkErr := r.checkRestoreTarget(ctx, aggregate); checkErr != nil {
+		return ctrl.Result{}, checkErr
+	} else if refusal != "" {
+		r.rejectAction(ctx, logger, aggregate, metav1.Condition{Type: ConditionRestoreTargetAllowed, ObservedGeneration: aggregate.Generation}, "RestoreTargetNotAllowed", refusal)
+		return ctrl.Result{}, nil
+	}
+
+	if aggregate.GetTargetNamespace() != aggregate.Namespace && !controllerutil.ContainsFinalizer(fresh, FinalizerChildrenInOtherNamespaces) {
+		controllerutil.AddFinalizer(fresh, FinalizerChildrenInOtherNamespaces)
+		if _, updateErr := r.BRClient.RequestedBackupActions(req.Namespace).Update(ctx, fresh, metav1.UpdateOptions{}); updateErr != nil {
+			return ctrl.Result{}, errors.Wrap(updateErr, "cannot add finalizer")
+		}
+	}
+
+	//
+	// 3. A restore is performed on a version selected by .spec.targetVersion, that has to exist in the Backup Repository
+	//
+	if resolved, resolveErr := r.resolveTargetVersion(ctx, logger, aggregate); !resolved {
+		if resolveErr != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 30}, resolveErr
+		}
+		return ctrl.Result{}, nil
+	}
+
 	//
-	// 2. Template & Create selected resources (only `kind: Job` type resources. The rest like Secrets and ConfigMaps we expect will be there already, created by ScheduledBackup)
+	// 4. Template & Create selected resources (only `kind: Job` type resources. The rest like Secrets and ConfigMaps we expect will be there already, created by ScheduledBackup)
+	//    With .spec.restoreTarget the action renders also its own Secrets and ConfigMaps in the target namespace
 	//
-	if applyErr := bmg.ApplyObjects(ctx, logger, r.Recorder, r.RestCfg, r.DynClient, aggregate); applyErr != nil {
+	aggregate.MarkAsRendering()
+	r.updateObjectStatus(ctx, logger, aggregate, metav1.Condition{
+		Status:  "Unknown",
+		Message: "Templating and applying objects",
+	})
+	if applyErr := bmg.ApplyObjects(ctx, logger, r.Recorder, r.RESTMapper, r.DynClient, r.Resources, aggregate, r.ConflictPolicy); applyErr != nil {
+		aggregate.MarkAsPending()
 		r.updateObjectStatus(ctx, logger, aggregate, metav1.Condition{
 			Status:  "False",
 			Message: fmt.Sprintf("Cannot find required dependencies: %s", applyErr.Error()),
@@ -113,10 +200,11 @@ func (r *RequestedBackupActionReconciler) Reconcile(ctx context.Context, req ctr
 	}
 
 	//
-	// 3. Update - mark as processed, update status and send notification event
+	// 5. Update - mark as processed, update status and send notification event
 	//
 	logger.Debug("Marking resource as processed")
 	aggregate.MarkAsProcessed()
+	aggregate.MarkAsStarted(metav1.Now())
 
 	r.updateObjectStatus(ctx, logger, aggregate, metav1.Condition{
 		Status:  "True",
@@ -126,6 +214,134 @@ func (r *RequestedBackupActionReconciler) Reconcile(ctx context.Context, req ctr
 	return ctrl.Result{}, nil
 }
 
+// ConditionTargetVersionResolved is True, when .spec.targetVersion of a restore was found in the Backup Repository
+const ConditionTargetVersionResolved = "TargetVersionResolved"
+
+// resolveTargetVersion is selecting the version to restore from versions stored in the Backup Repository, so a typo fails the action immediately
+// instead of failing the restore Job minutes later. The version is resolved once, every attempt restores the same version.
+// Returns false, when the action cannot be started - an error means, that it should be tried again
+func (r *RequestedBackupActionReconciler) resolveTargetVersion(ctx context.Context, logger *logrus.Entry, aggregate *domain.RequestedBackupActionAggregate) (bool, error) {
+	if aggregate.GetOperation() != domain.Restore || aggregate.Status.ResolvedVersion != nil {
+		return true, nil
+	}
+	condition := metav1.Condition{Type: ConditionTargetVersionResolved, ObservedGeneration: aggregate.Generation}
+
+	selector, parseErr := repository.ParseSelector(aggregate.Spec.TargetVersion)
+	if parseErr != nil {
+		r.rejectAction(ctx, logger, aggregate, condition, "InvalidTargetVersion", fmt.Sprintf("Invalid .spec.targetVersion: %s", parseErr.Error()))
+		return false, nil
+	}
+	access, accessErr := bmg.ResolveRepositoryAccess(logger, aggregate.Scheduled)
+	if accessErr != nil {
+		if selector.IsSymbolic() {
+			r.rejectAction(ctx, logger, aggregate, condition, "RepositoryNotAccessible",
+				fmt.Sprintf("'%s' can be resolved only by listing versions: %s", selector, accessErr.Error()))
+			return false, nil
+		}
+		// the restore script can still select a version by its number or "latest"
+		logger.Warnf("Cannot validate .spec.targetVersion: %s", accessErr.Error())
+		condition.Status, condition.Reason = metav1.ConditionUnknown, "NotValidated"
+		condition.Message = fmt.Sprintf("'%s' is passed to the restore as-is: %s", selector, accessErr.Error())
+		meta.SetStatusCondition(&aggregate.Status.Conditions, condition)
+		return true, nil
+	}
+
+	versions, listErr := repository.NewClient(access.URL, access.Token).ListVersions(ctx, access.CollectionId)
+	if listErr != nil {
+		r.Recorder.Event(aggregate.RequestedBackupAction, "Warning", "ErrorOccurred", listErr.Error())
+		return false, errors.Wrap(listErr, "cannot resolve .spec.targetVersion")
+	}
+	version, resolveErr := selector.Resolve(versions)
+	if resolveErr != nil {
+		r.rejectAction(ctx, logger, aggregate, condition, "VersionNotFound",
+			fmt.Sprintf("Cannot resolve .spec.targetVersion '%s': %s", selector, resolveErr.Error()))
+		return false, nil
+	}
+
+	resolved := version.ToBackupVersion()
+	aggregate.MarkAsResolved(resolved)
+	condition.Status, condition.Reason = metav1.ConditionTrue, "Resolved"
+	condition.Message = fmt.Sprintf("'%s' resolved to %s (id: %s, uploaded at %s)", selector, resolved.GetName(), resolved.Id, resolved.CreatedAt.UTC().Format(time.RFC3339))
+	meta.SetStatusCondition(&aggregate.Status.Conditions, condition)
+	r.Recorder.Event(aggregate.RequestedBackupAction, "Normal", "VersionResolved", condition.Message)
+	return true, nil
+}
+
+// ConditionKindTypeEnabled is False, when no enabled integration can run .spec.kindType
+const ConditionKindTypeEnabled = "KindTypeEnabled"
+
+// ConditionRestoreTargetAllowed is False, when the action was not allowed to restore into .spec.restoreTarget.namespace
+const ConditionRestoreTargetAllowed = "RestoreTargetAllowed"
+
+// checkRestoreTarget is returning a reason why the action cannot restore into other namespace, or an empty string when it can.
+// Permissions of the user are checked by the validating webhook. Without it, the target namespace has to allow restores explicitly
+func (r *RequestedBackupActionReconciler) checkRestoreTarget(ctx context.Context, aggregate *domain.RequestedBackupActionAggregate) (string, error) {
+	target := aggregate.GetTargetNamespace()
+	if target == aggregate.Namespace || r.RestoreTargetsValidatedByWebhook {
+		return "", nil
+	}
+	namespace := corev1.Namespace{}
+	if getErr := r.Client.Get(ctx, client.ObjectKey{Name: target}, &namespace); getErr != nil {
+		if apierrors.IsNotFound(getErr) {
+			return fmt.Sprintf("Namespace '%s' does not exist", target), nil
+		}
+		return "", errors.Wrapf(getErr, "cannot fetch Namespace '%s'", target)
+	}
+	for _, allowed := range strings.Split(namespace.Annotations[riotkitorgv1alpha1.AnnotationAllowRestoreFrom], ",") {
+		if strings.TrimSpace(allowed) == aggregate.Namespace {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("Namespace '%s' does not allow restores from namespace '%s'. Without admission webhooks the target namespace has to list it in '%s' annotation",
+		target, aggregate.Namespace, riotkitorgv1alpha1.AnnotationAllowRestoreFrom), nil
+}
+
+// rejectAction is failing the action without spawning a Job
+func (r *RequestedBackupActionReconciler) rejectAction(ctx context.Context, logger *logrus.Entry, aggregate *domain.RequestedBackupActionAggregate,
+	condition metav1.Condition, reason string, message string) {
+
+	logger.Error(message)
+	condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, reason, message
+	meta.SetStatusCondition(&aggregate.Status.Conditions, condition)
+	aggregate.MarkAsRejected(metav1.Now())
+	r.updateObjectStatus(ctx, logger, aggregate, metav1.Condition{
+		Status:  "False",
+		Message: message,
+	})
+	r.Recorder.Event(aggregate.RequestedBackupAction, "Warning", reason, message)
+}
+
+// FinalizerChildrenInOtherNamespaces is keeping the RequestedBackupAction until its children placed in .spec.restoreTarget.namespace are deleted
+const FinalizerChildrenInOtherNamespaces = "riotkit.org/children-in-other-namespaces"
+
+// finalize is deleting children placed in other namespaces, when the action is being deleted. Returns true, when the action is deleted or being deleted
+func (r *RequestedBackupActionReconciler) finalize(ctx context.Context, logger *logrus.Entry, req ctrl.Request) (bool, error) {
+	action, getErr := r.BRClient.RequestedBackupActions(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(getErr) {
+		return true, nil
+	}
+	if getErr != nil {
+		return false, errors.Wrap(getErr, "cannot fetch RequestedBackupAction from API")
+	}
+	if action.DeletionTimestamp == nil {
+		return false, nil
+	}
+	if !controllerutil.ContainsFinalizer(action, FinalizerChildrenInOtherNamespaces) {
+		return true, nil
+	}
+
+	logger.Info("Deleting objects placed in other namespaces")
+	if deleteErr := bmg.DeleteObjectsInOtherNamespaces(ctx, logger, r.RESTMapper, r.DynClient, action.Status.OwnedReferences); deleteErr != nil {
+		r.Recorder.Event(action, "Warning", "ErrorOccurred", deleteErr.Error())
+		return true, deleteErr
+	}
+	controllerutil.RemoveFinalizer(action, FinalizerChildrenInOtherNamespaces)
+	if _, updateErr := r.BRClient.RequestedBackupActions(req.Namespace).Update(ctx, action, metav1.UpdateOptions{}); updateErr != nil {
+		return true, errors.Wrap(updateErr, "cannot remove finalizer")
+	}
+	return true, nil
+}
+
 // updateObjectStatus is updating the .status field
 func (r *RequestedBackupActionReconciler) updateObjectStatus(ctx context.Context, logger *logrus.Entry, aggregate *domain.RequestedBackupActionAggregate, condition metav1.Condition) {
 	updateErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
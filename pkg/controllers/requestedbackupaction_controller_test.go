@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	brfake "github.com/riotkit-org/backup-maker-controller/pkg/client/clientset/versioned/fake"
+	"github.com/riotkit-org/backup-maker-controller/pkg/factory"
+	"github.com/riotkit-org/backup-maker-controller/pkg/integration"
+	"github.com/riotkit-org/backup-maker-controller/pkg/locking"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+// testCluster is a fake API: `cache` is serving ScheduledBackups, Secrets and Namespaces, `api` is serving RequestedBackupActions
+type testCluster struct {
+	cache    client.Client
+	api      *brfake.Clientset
+	recorder *record.FakeRecorder
+}
+
+func createTestCluster(t *testing.T, action *v1alpha1.RequestedBackupAction, objects ...client.Object) testCluster {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, clientgoscheme.AddToScheme(scheme))
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+
+	objects = append(objects,
+		&v1alpha1.ScheduledBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "production"},
+			Spec: v1alpha1.ScheduledBackupSpec{
+				Operation:       "backup",
+				TemplateRef:     v1alpha1.TemplateSpec{Kind: "internal", Name: "pg14"},
+				Vars:            "Params:\n    hostname: postgres\n",
+				GPGKeySecretRef: v1alpha1.GPGKeySecretSpec{SecretName: "backup-keys"},
+				TokenSecretRef:  v1alpha1.TokenSecretSpec{SecretName: "backup-token", TokenKey: "jwt"},
+			},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "backup-keys", Namespace: "production"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "backup-token", Namespace: "production"}},
+	)
+	return testCluster{
+		cache:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		api:      brfake.NewSimpleClientset(action),
+		recorder: record.NewFakeRecorder(10),
+	}
+}
+
+func (c testCluster) createReconciler(t *testing.T) *RequestedBackupActionReconciler {
+	return &RequestedBackupActionReconciler{
+		Client:       c.cache,
+		BRClient:     c.api.RiotkitV1alpha1(),
+		Fetcher:      factory.CachedFetcher{Cache: c.cache, Client: c.api.RiotkitV1alpha1()},
+		Recorder:     c.recorder,
+		Locker:       locking.NewInMemoryLocker(),
+		Integrations: createJobIntegration(t),
+	}
+}
+
+// createJobIntegration is enabling only Kubernetes Jobs, in a cluster that serves only Kubernetes Jobs
+func createJobIntegration(t *testing.T) *integration.AllSupportedJobResourceTypes {
+	discovery := &discoveryfake.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "jobs", Kind: "Job"}}},
+	}}}
+	integrations, err := integration.NewAllSupportedJobResourceTypes(&rest.Config{}, discovery, []string{"job"}, logrus.NewEntry(logrus.New()))
+	assert.Nil(t, err)
+	return &integrations
+}
+
+func (c testCluster) getAction(t *testing.T, name string) *v1alpha1.RequestedBackupAction {
+	action, err := c.api.RiotkitV1alpha1().RequestedBackupActions("production").Get(context.TODO(), name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	return action
+}
+
+func createRestoreIntoStaging() *v1alpha1.RequestedBackupAction {
+	return &v1alpha1.RequestedBackupAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1-clone", Namespace: "production"},
+		Spec: v1alpha1.RequestedBackupActionSpec{
+			Action:             "restore",
+			ScheduledBackupRef: v1alpha1.BackupRefSpec{Name: "app1"},
+			RestoreTarget:      &v1alpha1.RestoreTargetSpec{Namespace: "staging"},
+		},
+	}
+}
+
+// TestRequestedBackupActionReconciler_RestoreTargetWithoutWebhooks is checking that without webhooks the controller does not restore
+// into a namespace, that did not allow it - anyone could otherwise make the controller create Pods and Secrets in any namespace
+func TestRequestedBackupActionReconciler_RestoreTargetWithoutWebhooks(t *testing.T) {
+	cluster := createTestCluster(t, createRestoreIntoStaging(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "staging"}})
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app1-clone", Namespace: "production"}}
+
+	result, err := cluster.createReconciler(t).Reconcile(context.TODO(), req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	action := cluster.getAction(t, "app1-clone")
+	assert.Equal(t, v1alpha1.PhaseFailed, action.Status.Phase)
+	assert.Empty(t, action.Status.OwnedReferences, "Expected that nothing was applied")
+	assert.Empty(t, action.Finalizers)
+	condition := meta.FindStatusCondition(action.Status.Conditions, ConditionRestoreTargetAllowed)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "RestoreTargetNotAllowed", condition.Reason)
+	assert.Contains(t, condition.Message, "Namespace 'staging' does not allow restores from namespace 'production'")
+	assert.Contains(t, <-cluster.recorder.Events, "Warning RestoreTargetNotAllowed")
+}
+
+// TestRequestedBackupActionReconciler_CheckRestoreTarget is checking when the target namespace is accepted
+func TestRequestedBackupActionReconciler_CheckRestoreTarget(t *testing.T) {
+	cluster := createTestCluster(t, createRestoreIntoStaging(),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "staging", Annotations: map[string]string{
+			v1alpha1.AnnotationAllowRestoreFrom: "development, production",
+		}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	)
+	reconciler := cluster.createReconciler(t)
+	aggregate, _, err := reconciler.fetchAggregate(context.TODO(), createLogger(context.TODO(), ctrl.Request{}, "test"),
+		ctrl.Request{NamespacedName: types.NamespacedName{Name: "app1-clone", Namespace: "production"}})
+	assert.Nil(t, err)
+
+	// the namespace allows restores from "production"
+	refusal, err := reconciler.checkRestoreTarget(context.TODO(), aggregate)
+	assert.Nil(t, err)
+	assert.Empty(t, refusal)
+
+	aggregate.Spec.RestoreTarget.Namespace = "kube-system"
+	refusal, _ = reconciler.checkRestoreTarget(context.TODO(), aggregate)
+	assert.Contains(t, refusal, "does not allow restores")
+
+	aggregate.Spec.RestoreTarget.Namespace = "not-existing"
+	refusal, _ = reconciler.checkRestoreTarget(context.TODO(), aggregate)
+	assert.Equal(t, "Namespace 'not-existing' does not exist", refusal)
+
+	// the same namespace is always allowed
+	aggregate.Spec.RestoreTarget.Namespace = "production"
+	refusal, _ = reconciler.checkRestoreTarget(context.TODO(), aggregate)
+	assert.Empty(t, refusal)
+
+	// the webhook already checked permissions of the user, who created the action
+	aggregate.Spec.RestoreTarget.Namespace = "kube-system"
+	reconciler.RestoreTargetsValidatedByWebhook = true
+	refusal, _ = reconciler.checkRestoreTarget(context.TODO(), aggregate)
+	assert.Empty(t, refusal)
+}
+
+// TestRequestedBackupActionReconciler_KindTypeNotEnabled is checking that the action is failed with a clear reason,
+// when its .spec.kindType cannot be performed by any enabled integration
+func TestRequestedBackupActionReconciler_KindTypeNotEnabled(t *testing.T) {
+	action := createRestoreIntoStaging()
+	action.Spec.RestoreTarget = nil
+	action.Spec.KindType = v1alpha1.KindTypeWorkflow
+	cluster := createTestCluster(t, action)
+
+	_, err := cluster.createReconciler(t).Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "app1-clone", Namespace: "production"}})
+
+	assert.Nil(t, err)
+	action = cluster.getAction(t, "app1-clone")
+	assert.Equal(t, v1alpha1.PhaseFailed, action.Status.Phase)
+	assert.Empty(t, action.Status.OwnedReferences, "Expected that nothing was applied")
+	condition := meta.FindStatusCondition(action.Status.Conditions, ConditionKindTypeEnabled)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "KindTypeNotEnabled", condition.Reason)
+	assert.Contains(t, condition.Message, "No enabled integration can run .spec.kindType 'Workflow', available: [Job]")
+	assert.Contains(t, <-cluster.recorder.Events, "Warning KindTypeNotEnabled")
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"github.com/pkg/errors"
 	riotkitorgv1alpha1 "github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
 	"github.com/riotkit-org/backup-maker-controller/pkg/client/clientset/versioned/typed/riotkit/v1alpha1"
@@ -9,10 +10,13 @@ import (
 	"github.com/riotkit-org/backup-maker-controller/pkg/factory"
 	"github.com/riotkit-org/backup-maker-controller/pkg/integration"
 	"github.com/riotkit-org/backup-maker-controller/pkg/locking"
+	"github.com/riotkit-org/backup-maker-controller/pkg/metrics"
+	"github.com/riotkit-org/backup-maker-controller/pkg/notification"
 	"github.com/sirupsen/logrus"
-	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/util/retry"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -31,8 +35,13 @@ type JobsManagedByRequestedBackupActionObserver struct {
 	Integrations *integration.AllSupportedJobResourceTypes
 	Fetcher      factory.CachedFetcher
 	Locker       locking.Locker
+	Recorder     record.EventRecorder
+	Notifier     *notification.Sender
 }
 
+// +kubebuilder:rbac:groups=,resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=riotkit.org,resources=notificationchannels,verbs=get;list;watch
+
 func (r *JobsManagedByRequestedBackupActionObserver) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := createLogger(ctx, req, "JobsManagedByRequestedBackupActionObserver")
 
@@ -56,15 +65,72 @@ func (r *JobsManagedByRequestedBackupActionObserver) Reconcile(ctx context.Conte
 	if err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "cannot fetch RequestedBackupAction from cache")
 	}
+	if !aggregate.WasAlreadyProcessed() {
+		// the .status belongs to RequestedBackupActionReconciler until the Job is applied
+		logger.Debugf("Not processed yet, nothing to observe")
+		return ctrl.Result{}, nil
+	}
 
 	//
 	// 2. Collect the report about all managed resources in our context
 	//
 	ownedReferences := aggregate.GetReferencesOfOwnedObjects()
 	report, healthy, err := createOwnedReferencesHealthReport(ctx, ownedReferences, r.Integrations, logger, req.Namespace)
+	if err != nil {
+		// a partial report could mark the action as finished, or spawn a needless retry
+		return ctrl.Result{}, errors.Wrap(err, "cannot check health of owned objects")
+	}
+
+	// killing the Jobs and scheduling a retry has to be decided on the current attempt - a retry of an outdated one
+	// would clear references to the Job of the next attempt, and the reconciler would spawn another one
+	fresh, getErr := r.BRClient.RequestedBackupActions(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
+	if getErr != nil {
+		return ctrl.Result{}, errors.Wrap(getErr, "cannot fetch RequestedBackupAction from API")
+	}
+	if fresh.ResourceVersion != aggregate.ResourceVersion {
+		logger.Debugf("Object was modified during the observation, requeuing")
+		return ctrl.Result{RequeueAfter: time.Second * 2}, nil
+	}
+
+	//
+	// 3. Move the action through its lifecycle: Running -> Succeeded/Failed/TimedOut
+	//
+	var finished bool
+	if aggregate.IsDeadlineExceeded(time.Now()) {
+		// a hung process would never end by itself, it has to be killed
+		if killErr := r.killChildren(ctx, aggregate, logger); killErr != nil {
+			return ctrl.Result{}, errors.Wrap(killErr, "cannot kill objects that exceeded the timeout")
+		}
+		aggregate.MarkAsTimedOut(metav1.Now())
+		r.Recorder.Event(aggregate.RequestedBackupAction, "Warning", "DeadlineExceeded", fmt.Sprintf("'%s' was running longer than %s, killed",
+			aggregate.Status.JobName, aggregate.GetTimeout().Duration))
+		finished = true
+	} else {
+		finished = aggregate.UpdatePhaseFromHealthReport(report, metav1.Now())
+	}
+	if finished && aggregate.CanRetry() {
+		// RequestedBackupActionReconciler will spawn a fresh Job after the backoff
+		phase := aggregate.Status.Phase
+		run := createRun(aggregate)
+		aggregate.ScheduleRetry()
+		if updateErr := r.updateStatus(ctx, aggregate, report, healthy, logger); updateErr != nil {
+			return requeueOnConflict(updateErr, logger)
+		}
+		recordRunMetrics(aggregate, run)
+		r.Recorder.Event(aggregate.RequestedBackupAction, "Warning", "Retrying", fmt.Sprintf("Attempt %d of %d finished with %s, retrying",
+			aggregate.Status.Attempts, aggregate.Spec.RetryPolicy.MaxAttempts, phase))
+		return ctrl.Result{}, nil
+	}
+	if updateErr := r.updateStatus(ctx, aggregate, report, healthy, logger); updateErr != nil {
+		return requeueOnConflict(updateErr, logger)
+	}
+	if finished {
+		recordRunMetrics(aggregate, createRun(aggregate))
+		r.notifyFinished(aggregate, report, logger)
+	}
 
 	//
-	// 3. The Jobs are still running, wait for them to be finished (in next controller iteration - REQUEUE)
+	// 4. The Jobs are still running, wait for them to be finished (in next controller iteration - REQUEUE)
 	//
 	for _, healthStatus := range report {
 		if healthStatus.Running {
@@ -72,34 +138,109 @@ func (r *JobsManagedByRequestedBackupActionObserver) Reconcile(ctx context.Conte
 		}
 	}
 
-	// Update the status
-	r.updateStatus(ctx, aggregate, report, healthy, logger)
-
 	return ctrl.Result{}, nil
 }
 
-func (r *JobsManagedByRequestedBackupActionObserver) updateStatus(ctx context.Context, aggregate *domain.RequestedBackupActionAggregate, report []riotkitorgv1alpha1.JobHealthStatus, healthy bool, logger *logrus.Entry) {
-	retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		res, getErr := r.BRClient.RequestedBackupActions(aggregate.Namespace).Get(ctx, aggregate.Name, metav1.GetOptions{})
-		if getErr != nil {
-			return getErr
+// updateStatus is writing the status computed from the fetched object. The write is rejected with a conflict, when the object was
+// modified in the meantime - the phase transitions were made on an outdated status then, and have to be computed again
+func (r *JobsManagedByRequestedBackupActionObserver) updateStatus(ctx context.Context, aggregate *domain.RequestedBackupActionAggregate, report []riotkitorgv1alpha1.JobHealthStatus, healthy bool, logger *logrus.Entry) error {
+	aggregate.Status.ChildrenResourcesHealth = report
+	aggregate.Status.Healthy = healthy
+
+	if _, updateErr := r.BRClient.RequestedBackupActions(aggregate.Namespace).UpdateStatus(ctx, aggregate.RequestedBackupAction, metav1.UpdateOptions{}); updateErr != nil {
+		return errors.Wrap(updateErr, "cannot update .status")
+	}
+	logger.Debugf(".status field updated with .ChildrenResourcesHealth, .Healthy and .Phase = %s", aggregate.Status.Phase)
+	return nil
+}
+
+// requeueOnConflict is observing the object once again, when it was modified during the observation
+func requeueOnConflict(err error, logger *logrus.Entry) (ctrl.Result, error) {
+	if apierrors.IsConflict(err) {
+		logger.Debugf("Object was modified in the meantime, requeuing")
+		return ctrl.Result{RequeueAfter: time.Second * 2}, nil
+	}
+	return ctrl.Result{}, err
+}
+
+// killChildren is deleting all objects spawned by the current attempt, together with their Pods
+func (r *JobsManagedByRequestedBackupActionObserver) killChildren(ctx context.Context, aggregate *domain.RequestedBackupActionAggregate, logger *logrus.Entry) error {
+	for _, ref := range aggregate.GetReferencesOfOwnedObjects() {
+		list := unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(ref.GetGVK().GroupVersion().WithKind(ref.Kind + "List"))
+		if listErr := r.Client.List(ctx, &list, client.InNamespace(ref.GetNamespace(aggregate.Namespace)), client.MatchingLabels{riotkitorgv1alpha1.LabelTrackingId: ref.TrackingId}); listErr != nil {
+			return errors.Wrapf(listErr, "cannot list %s labelled with %s=%s", ref.Kind, riotkitorgv1alpha1.LabelTrackingId, ref.TrackingId)
+		}
+		for i := range list.Items {
+			logger.Infof("Killing %s %s/%s", ref.Kind, list.Items[i].GetNamespace(), list.Items[i].GetName())
+			deleteErr := r.Client.Delete(ctx, &list.Items[i], client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if client.IgnoreNotFound(deleteErr) != nil {
+				return errors.Wrapf(deleteErr, "cannot delete %s '%s'", ref.Kind, list.Items[i].GetName())
+			}
 		}
-		res.Status = aggregate.RequestedBackupAction.Status
-		res.Status.ChildrenResourcesHealth = report
-		res.Status.Healthy = healthy
+	}
+	return nil
+}
 
-		_, updateErr := r.BRClient.RequestedBackupActions(aggregate.Namespace).UpdateStatus(ctx, res, metav1.UpdateOptions{})
-		logger.Debugf(".status field updated with .ChildrenResourcesHealth and .Healthy")
-		return updateErr
+// notifyFinished is sending an event and notifications, when the action reached a terminal phase.
+// Channels of the ScheduledBackup are notified, when the action does not refer to its own
+func (r *JobsManagedByRequestedBackupActionObserver) notifyFinished(aggregate *domain.RequestedBackupActionAggregate, report []riotkitorgv1alpha1.JobHealthStatus, logger *logrus.Entry) {
+	refs := aggregate.Spec.NotificationChannelRefs
+	if len(refs) == 0 {
+		refs = aggregate.GetScheduledBackup().Spec.NotificationChannelRefs
+	}
+	sendNotification(r.Notifier, r.Recorder, logger, aggregate.RequestedBackupAction, refs, notification.Notification{
+		Kind:            "RequestedBackupAction",
+		Namespace:       aggregate.Namespace,
+		Name:            aggregate.Name,
+		ScheduledBackup: aggregate.Spec.ScheduledBackupRef.Name,
+		Operation:       aggregate.Spec.Action,
+		Outcome:         riotkitorgv1alpha1.RunOutcome(aggregate.Status.Phase),
+		JobName:         aggregate.Status.JobName,
+		StartTime:       toTime(aggregate.Status.StartTime),
+		CompletionTime:  toTime(aggregate.Status.CompletionTime),
+		Message:         describeFailures(report),
 	})
+
+	phase := aggregate.Status.Phase
+	duration := aggregate.Status.CompletionTime.Sub(aggregate.Status.StartTime.Time).Round(time.Second)
+	if phase == riotkitorgv1alpha1.PhaseSucceeded {
+		r.Recorder.Event(aggregate.RequestedBackupAction, "Normal", string(phase), fmt.Sprintf("%s of '%s' finished after %s", aggregate.Spec.Action, aggregate.Status.JobName, duration))
+		return
+	}
+	r.Recorder.Event(aggregate.RequestedBackupAction, "Warning", string(phase), fmt.Sprintf("%s of '%s' did not succeed after %s", aggregate.Spec.Action, aggregate.Status.JobName, duration))
+}
+
+// createRun is describing a finished attempt for the metrics, nil when the attempt has no timing
+func createRun(aggregate *domain.RequestedBackupActionAggregate) *metrics.Run {
+	if aggregate.Status.StartTime == nil || aggregate.Status.CompletionTime == nil {
+		return nil
+	}
+	return &metrics.Run{
+		Succeeded:      aggregate.Status.Phase == riotkitorgv1alpha1.PhaseSucceeded,
+		StartTime:      aggregate.Status.StartTime.Time,
+		CompletionTime: aggregate.Status.CompletionTime.Time,
+	}
+}
+
+// recordRunMetrics is reporting a finished attempt to Prometheus, the same way as runs of CronJobs
+func recordRunMetrics(aggregate *domain.RequestedBackupActionAggregate, run *metrics.Run) {
+	if run == nil {
+		return
+	}
+	metrics.RecordRuns(metrics.Labels(aggregate.GetScheduledBackup(), aggregate.Spec.Action), []metrics.Run{*run})
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *JobsManagedByRequestedBackupActionObserver) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&riotkitorgv1alpha1.RequestedBackupAction{}).
-		Owns(&batchv1.Job{}).
-		Owns(&batchv1.CronJob{}).
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&riotkitorgv1alpha1.RequestedBackupAction{})
+
+	// children objects of enabled integrations, e.g. Jobs, CronJobs, Argo Workflows
+	for _, owned := range r.Integrations.GetOwnedObjects() {
+		builder = builder.Owns(owned)
+	}
+	return builder.
 		WithEventFilter(predicate.Funcs{
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				return false
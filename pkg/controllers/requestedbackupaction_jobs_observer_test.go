@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	brfake "github.com/riotkit-org/backup-maker-controller/pkg/client/clientset/versioned/fake"
+	"github.com/riotkit-org/backup-maker-controller/pkg/factory"
+	"github.com/riotkit-org/backup-maker-controller/pkg/integration"
+	"github.com/riotkit-org/backup-maker-controller/pkg/locking"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"testing"
+	"time"
+)
+
+func (c testCluster) createObserver() *JobsManagedByRequestedBackupActionObserver {
+	return &JobsManagedByRequestedBackupActionObserver{
+		Client:       c.cache,
+		BRClient:     c.api.RiotkitV1alpha1(),
+		Integrations: &integration.AllSupportedJobResourceTypes{},
+		Fetcher:      factory.CachedFetcher{Cache: c.cache, Client: c.api.RiotkitV1alpha1()},
+		Recorder:     c.recorder,
+		Locker:       locking.NewInMemoryLocker(),
+	}
+}
+
+func createBackupAction(status v1alpha1.RequestedBackupActionStatus) *v1alpha1.RequestedBackupAction {
+	return &v1alpha1.RequestedBackupAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1-now", Namespace: "production", ResourceVersion: "1"},
+		Spec: v1alpha1.RequestedBackupActionSpec{
+			Action:             "backup",
+			ScheduledBackupRef: v1alpha1.BackupRefSpec{Name: "app1"},
+		},
+		Status: status,
+	}
+}
+
+// TestJobsManagedByRequestedBackupActionObserver_NotProcessedYet is checking that the observer does not write the .status,
+// while RequestedBackupActionReconciler is still applying the objects - it would overwrite the status with an outdated one
+func TestJobsManagedByRequestedBackupActionObserver_NotProcessedYet(t *testing.T) {
+	cluster := createTestCluster(t, createBackupAction(v1alpha1.RequestedBackupActionStatus{Phase: v1alpha1.PhaseRendering}))
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "app1-now", Namespace: "production"}}
+
+	result, err := cluster.createObserver().Reconcile(context.TODO(), req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	for _, action := range cluster.api.Actions() {
+		assert.NotEqual(t, "update", action.GetVerb(), "Expected that the observer does not update the object")
+	}
+}
+
+// TestJobsManagedByRequestedBackupActionObserver_DoesNotRetryOutdatedAttempt is checking that a timed out attempt is not killed
+// and retried, when the API has already a newer attempt - the retry would drop the Job of the newer attempt
+func TestJobsManagedByRequestedBackupActionObserver_DoesNotRetryOutdatedAttempt(t *testing.T) {
+	startedAt := metav1.NewTime(time.Now().Add(time.Hour * -1))
+	createAttempt := func(attempt int, trackingId string) v1alpha1.RequestedBackupActionStatus {
+		return v1alpha1.RequestedBackupActionStatus{
+			Processed: true,
+			Phase:     v1alpha1.PhaseRunning,
+			Attempts:  attempt,
+			StartTime: &startedAt,
+			History:   []v1alpha1.ActionAttempt{{Attempt: attempt, Phase: v1alpha1.PhaseRunning, StartTime: &startedAt, TrackingId: trackingId}},
+			OwnedReferences: v1alpha1.ChildrenReferences{
+				{APIVersion: "batch/v1", Kind: "Job", TrackingId: trackingId, Name: "app1-now-" + trackingId},
+			},
+		}
+	}
+	withPolicy := func(action *v1alpha1.RequestedBackupAction) *v1alpha1.RequestedBackupAction {
+		action.Spec.Timeout = &metav1.Duration{Duration: time.Minute}
+		action.Spec.RetryPolicy = &v1alpha1.RetryPolicySpec{MaxAttempts: 3}
+		return action
+	}
+
+	// the API has already the second attempt, while the observer got the first one
+	current := withPolicy(createBackupAction(createAttempt(2, "bbb")))
+	current.ResourceVersion = "2"
+	outdated := withPolicy(createBackupAction(createAttempt(1, "aaa")))
+
+	cluster := createTestCluster(t, current, createTrackedJob("app1-now-aaa", "aaa"), createTrackedJob("app1-now-bbb", "bbb"))
+	observer := cluster.createObserver()
+	observer.Fetcher = factory.CachedFetcher{Cache: cluster.cache, Client: brfake.NewSimpleClientset(outdated).RiotkitV1alpha1()}
+
+	result, err := observer.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "app1-now", Namespace: "production"}})
+
+	assert.Nil(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+	action := cluster.getAction(t, "app1-now")
+	assert.Equal(t, v1alpha1.PhaseRunning, action.Status.Phase)
+	assert.True(t, action.Status.Processed)
+	assert.Equal(t, "bbb", action.Status.OwnedReferences[0].TrackingId, "Expected that references of the current attempt are kept")
+
+	jobs := batchv1.JobList{}
+	assert.Nil(t, cluster.cache.List(context.TODO(), &jobs))
+	assert.Len(t, jobs.Items, 2, "Expected that no Job was killed")
+}
+
+func createTrackedJob(name string, trackingId string) *batchv1.Job {
+	return &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "production", Labels: map[string]string{
+		v1alpha1.LabelTrackingId: trackingId,
+	}}}
+}
@@ -26,11 +26,11 @@ import (
 	"github.com/riotkit-org/backup-maker-controller/pkg/factory"
 	"github.com/riotkit-org/backup-maker-controller/pkg/locking"
 	"github.com/sirupsen/logrus"
+	"io/fs"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -45,14 +45,20 @@ import (
 // ScheduledBackupReconciler reconciles a ScheduledBackup object
 type ScheduledBackupReconciler struct {
 	client.Client
-	RestCfg   *rest.Config
-	DynClient dynamic.Interface
-	BRClient  v1alpha1.RiotkitV1alpha1Interface
-	Scheme    *runtime.Scheme
-	Cache     cache.Cache
-	Fetcher   factory.CachedFetcher
-	Recorder  record.EventRecorder
-	Locker    locking.Locker
+	RESTMapper meta.ResettableRESTMapper
+	DynClient  dynamic.Interface
+	BRClient   v1alpha1.RiotkitV1alpha1Interface
+	Scheme     *runtime.Scheme
+	Cache      cache.Cache
+	Fetcher    factory.CachedFetcher
+	Recorder   record.EventRecorder
+	Locker     locking.Locker
+
+	// Resources are templates and the Helm chart of Backup Maker Generator, used to render children objects
+	Resources fs.FS
+
+	// ConflictPolicy decides if fields owned by other field managers are taken over, when applying children objects
+	ConflictPolicy bmg.ConflictPolicy
 }
 
 // +kubebuilder:rbac:groups=,resources=secrets,verbs=get;list;watch
@@ -81,7 +87,6 @@ func (r *ScheduledBackupReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	defer r.Locker.Done(ctx, lock)
 
 	// todo: support case, when cron=false. Then do not create CronJob or Job objects. Such case would mean manual triggering of the backup process
-	// todo: backup rotation - basing on the server settings?
 
 	//
 	// Fetch reconciled object [ScheduledBackup]
@@ -116,7 +121,10 @@ func (r *ScheduledBackupReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			return ctrl.Result{RequeueAfter: time.Minute * 15}, err
 		}
 
-		if applyErr := bmg.ApplyObjects(ctx, logger, r.Recorder, r.RestCfg, r.DynClient, aggregate); applyErr != nil {
+		// children list is built from scratch, so the objects that are no longer rendered could be found and deleted
+		previousReferences := aggregate.ForgetOwnedObjects()
+
+		if applyErr := bmg.ApplyObjects(ctx, logger, r.Recorder, r.RESTMapper, r.DynClient, r.Resources, aggregate, r.ConflictPolicy); applyErr != nil {
 			r.updateObject(ctx, aggregate, metav1.Condition{
 				Status:  "False",
 				Message: fmt.Sprintf("Cannot template or apply objects to the cluster: %s", applyErr.Error()),
@@ -124,6 +132,14 @@ func (r *ScheduledBackupReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			r.Recorder.Event(backup, "Warning", "ErrorOccurred", applyErr.Error())
 			return ctrl.Result{RequeueAfter: time.Minute * 1}, nil
 		}
+		if pruneErr := bmg.PruneOrphanedObjects(ctx, logger, r.Recorder, r.RESTMapper, r.DynClient, aggregate, previousReferences); pruneErr != nil {
+			r.updateObject(ctx, aggregate, metav1.Condition{
+				Status:  "False",
+				Message: fmt.Sprintf("Cannot delete objects that are no longer rendered: %s", pruneErr.Error()),
+			})
+			r.Recorder.Event(backup, "Warning", "ErrorOccurred", pruneErr.Error())
+			return ctrl.Result{RequeueAfter: time.Minute * 1}, nil
+		}
 
 		// todo: handle panics
 
@@ -155,6 +171,7 @@ func (r *ScheduledBackupReconciler) updateObject(ctx context.Context, aggregate
 		if condition.Status == "True" {
 			// todo: move to domain
 			res.Status.LastAppliedSpecHash = aggregate.Spec.CalculateHash()
+			res.Status.OwnedReferences = aggregate.Status.OwnedReferences
 		}
 		meta.SetStatusCondition(&res.Status.Conditions, condition)
 
@@ -2,21 +2,35 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"github.com/pkg/errors"
 	riotkitorgv1alpha1 "github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/bmg"
 	"github.com/riotkit-org/backup-maker-controller/pkg/client/clientset/versioned/typed/riotkit/v1alpha1"
 	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
 	"github.com/riotkit-org/backup-maker-controller/pkg/factory"
 	"github.com/riotkit-org/backup-maker-controller/pkg/integration"
 	"github.com/riotkit-org/backup-maker-controller/pkg/locking"
+	"github.com/riotkit-org/backup-maker-controller/pkg/metrics"
+	"github.com/riotkit-org/backup-maker-controller/pkg/notification"
+	"github.com/riotkit-org/backup-maker-controller/pkg/repository"
 	"github.com/sirupsen/logrus"
-	batchv1 "k8s.io/api/batch/v1"
+	"io/fs"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"strings"
 	"time"
 )
 
@@ -31,6 +45,20 @@ type JobsManagedByScheduledBackupObserver struct {
 	Integrations *integration.AllSupportedJobResourceTypes
 	Fetcher      factory.CachedFetcher
 	Locker       locking.Locker
+	Recorder     record.EventRecorder
+	Notifier     *notification.Sender
+
+	// RepositoryVersionsLimit is a number of the newest versions published in .status.repository, zero disables listing the versions
+	RepositoryVersionsLimit int
+	// RepositorySyncInterval is how often the versions are listed again. Those are listed also after each successful backup
+	RepositorySyncInterval time.Duration
+
+	// RetentionJobImage is the controller's image, used by Jobs applying .spec.retention when Backup Repository is not accessible
+	// by the controller. Such Jobs are not created, when empty
+	RetentionJobImage string
+	RESTMapper        meta.ResettableRESTMapper
+	DynClient         dynamic.Interface
+	Resources         fs.FS
 }
 
 func (r *JobsManagedByScheduledBackupObserver) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -54,15 +82,45 @@ func (r *JobsManagedByScheduledBackupObserver) Reconcile(ctx context.Context, re
 
 	logger.Info("Reconciling children")
 
+	// A deleted ScheduledBackup will not report anything anymore, its metrics would stay frozen forever
+	if _, getErr := r.Fetcher.FetchScheduledBackup(ctx, req); apierrors.IsNotFound(getErr) {
+		logger.Info("ScheduledBackup was deleted, forgetting its metrics")
+		metrics.Forget(req.Namespace, req.Name)
+		return ctrl.Result{}, nil
+	}
+
 	// Fetch and populate the context
 	aggregate, err := factory.FetchSBAggregate(ctx, r.Fetcher, r.Client, logger, req)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "cannot fetch ScheduledBackup from cache")
 	}
+	metrics.TrackTemplate(aggregate.ScheduledBackup)
 
 	// Collect the report about all managed resources in our context
 	ownedReferences := aggregate.GetReferencesOfOwnedObjects()
 	report, healthy, err := createOwnedReferencesHealthReport(ctx, ownedReferences, r.Integrations, logger, req.Namespace)
+	if err != nil {
+		// a partial report would be written into the status, and could trigger the retention or a missed backup alert
+		return ctrl.Result{}, errors.Wrap(err, "cannot check health of owned objects")
+	}
+
+	// Kubernetes keeps only a few last Jobs of a CronJob, so its runs are collected into the history at every iteration
+	cronJobRuns := r.observeCronJobs(ctx, logger, aggregate.ScheduledBackup, ownedReferences)
+
+	// Backups and restores performed manually - those are also uploading versions, or are restoring them
+	actions, actionsErr := r.listActions(ctx, aggregate.ScheduledBackup)
+	if actionsErr != nil {
+		return ctrl.Result{}, actionsErr
+	}
+
+	// Old versions are deleted after each successful backup, before listing the versions
+	retention := r.applyRetention(ctx, logger, aggregate, cronJobRuns, actions)
+
+	// Versions stored in the Backup Repository, to let the users pick a version to restore
+	versions := r.listVersions(ctx, logger, aggregate, cronJobRuns, actions, retention.hasRemovedVersions())
+
+	// Update the status
+	ageReport := r.updateStatus(ctx, logger, aggregate, report, healthy, cronJobRuns, versions, retention)
 
 	// The Jobs are still running, wait for them to be finished
 	for _, healthStatus := range report {
@@ -71,37 +129,412 @@ func (r *JobsManagedByScheduledBackupObserver) Reconcile(ctx context.Context, re
 		}
 	}
 
-	// Update the status
-	r.updateStatus(ctx, logger, aggregate, report, healthy)
+	// A missed backup does not produce any event, the age of the backup needs to be checked again later
+	var requeueAfter time.Duration
+	if ageReport != nil && !ageReport.NextCheck.IsZero() {
+		requeueAfter = time.Until(ageReport.NextCheck) + time.Second
+	}
+	// Versions can be uploaded or deleted by anyone, not only by our CronJobs
+	repositoryAccessible := versions == nil || versions.condition.Reason != reasonRepositoryNotAccessible
+	if r.RepositoryVersionsLimit > 0 && repositoryAccessible && (requeueAfter == 0 || r.RepositorySyncInterval < requeueAfter) {
+		requeueAfter = r.RepositorySyncInterval
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// ConditionRepositoryReachable is True, when the versions were listed from the Backup Repository
+const ConditionRepositoryReachable = "RepositoryReachable"
+
+// reasonRepositoryNotAccessible means, that the controller does not know how to reach the Backup Repository, the scripts in Pods still can
+const reasonRepositoryNotAccessible = "NotAccessible"
+
+// listedVersions is a result of listing versions from the Backup Repository
+type listedVersions struct {
+	// status is nil, when the versions could not be listed - the previously published versions are kept
+	status    *riotkitorgv1alpha1.RepositoryStatus
+	condition metav1.Condition
+}
+
+// listVersions is listing versions stored in the Backup Repository, when the published list is outdated, a backup has finished since
+// the last listing or the retention has just deleted versions. Returns nil, when the published versions are up-to-date
+func (r *JobsManagedByScheduledBackupObserver) listVersions(ctx context.Context, logger *logrus.Entry, aggregate *domain.ScheduledBackupAggregate, cronJobRuns map[string]riotkitorgv1alpha1.CronJobStatus,
+	actions []riotkitorgv1alpha1.RequestedBackupAction, force bool) *listedVersions {
+
+	if r.RepositoryVersionsLimit <= 0 {
+		return nil
+	}
+	published := aggregate.Status.Repository
+	if !force && !published.IsOutdated(time.Now(), r.RepositorySyncInterval) && !hasBackupFinishedSince(aggregate.ScheduledBackup, cronJobRuns, actions, published.LastSyncTime.Time) {
+		return nil
+	}
+	condition := metav1.Condition{
+		Type:               ConditionRepositoryReachable,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: aggregate.Generation,
+	}
+
+	access, accessErr := bmg.ResolveRepositoryAccess(logger, aggregate)
+	if accessErr != nil {
+		logger.Debugf("Cannot list versions: %s", accessErr.Error())
+		condition.Reason, condition.Message = reasonRepositoryNotAccessible, accessErr.Error()
+		return &listedVersions{condition: condition}
+	}
+	versions, listErr := repository.NewClient(access.URL, access.Token).ListVersions(ctx, access.CollectionId)
+	if listErr != nil {
+		logger.Warnf("Cannot list versions: %s", listErr.Error())
+		condition.Reason, condition.Message = "ListingFailed", listErr.Error()
+		return &listedVersions{condition: condition}
+	}
+	condition.Status, condition.Reason = metav1.ConditionTrue, "VersionsListed"
+	condition.Message = fmt.Sprintf("Collection '%s' contains %d versions", access.CollectionId, len(versions))
+	return &listedVersions{status: repository.NewRepositoryStatus(versions, r.RepositoryVersionsLimit, time.Now()), condition: condition}
+}
+
+// hasBackupFinishedSince tells if any CronJob or RequestedBackupAction performing a backup has successfully finished after given time
+func hasBackupFinishedSince(backup *riotkitorgv1alpha1.ScheduledBackup, cronJobRuns map[string]riotkitorgv1alpha1.CronJobStatus,
+	actions []riotkitorgv1alpha1.RequestedBackupAction, since time.Time) bool {
+
+	return lastBackupFinishedAt(backup, cronJobRuns, actions).After(since)
+}
+
+// lastBackupFinishedAt is returning the completion time of the newest successful backup performed by any CronJob or RequestedBackupAction,
+// zero when there is none
+func lastBackupFinishedAt(backup *riotkitorgv1alpha1.ScheduledBackup, cronJobRuns map[string]riotkitorgv1alpha1.CronJobStatus,
+	actions []riotkitorgv1alpha1.RequestedBackupAction) time.Time {
+
+	var last time.Time
+	for _, action := range actions {
+		if action.Spec.Action != string(domain.Backup) || action.Status.Phase != riotkitorgv1alpha1.PhaseSucceeded {
+			continue
+		}
+		if action.Status.CompletionTime != nil && action.Status.CompletionTime.After(last) {
+			last = action.Status.CompletionTime.Time
+		}
+	}
+	for _, schedule := range backup.Spec.GetSchedules() {
+		if schedule.Operation != string(domain.Backup) {
+			continue
+		}
+		for _, run := range cronJobRuns[schedule.Name].LastRuns {
+			if run.Outcome == riotkitorgv1alpha1.RunSucceeded && run.CompletionTime != nil && run.CompletionTime.After(last) {
+				last = run.CompletionTime.Time
+			}
+		}
+	}
+	return last
+}
+
+// ConditionRetentionApplied is True, when .spec.retention was applied after the newest successful backup
+const ConditionRetentionApplied = "RetentionApplied"
+
+// appliedRetention is a result of applying .spec.retention. A failed application is not retried until the next successful backup
+type appliedRetention struct {
+	status    *riotkitorgv1alpha1.RetentionStatus
+	condition metav1.Condition
+}
+
+func (a *appliedRetention) hasRemovedVersions() bool {
+	return a != nil && !a.status.DryRun && len(a.status.Removed) > 0
+}
+
+// applyRetention is deleting versions not kept by .spec.retention, after a backup has successfully finished since the previous application.
+// When the controller cannot reach the Backup Repository, then a Job is doing it from the backup Pod's environment.
+// Returns nil, when there is nothing to do
+func (r *JobsManagedByScheduledBackupObserver) applyRetention(ctx context.Context, logger *logrus.Entry, aggregate *domain.ScheduledBackupAggregate, cronJobRuns map[string]riotkitorgv1alpha1.CronJobStatus,
+	actions []riotkitorgv1alpha1.RequestedBackupAction) *appliedRetention {
+
+	policy := aggregate.Spec.Retention
+	backupFinishedAt := lastBackupFinishedAt(aggregate.ScheduledBackup, cronJobRuns, actions)
+	if policy == nil || !backupFinishedAt.After(aggregate.Status.Retention.GetLastAppliedTime()) {
+		return nil
+	}
+	// the cached object could miss the previous application, e.g. when the observer was triggered again just after updating the status
+	fresh, getErr := r.BRClient.ScheduledBackups(aggregate.Namespace).Get(ctx, aggregate.Name, metav1.GetOptions{})
+	if getErr != nil {
+		logger.Warnf("Cannot check when the retention was applied: %s", getErr.Error())
+		return nil
+	}
+	if !backupFinishedAt.After(fresh.Status.Retention.GetLastAppliedTime()) {
+		return nil
+	}
+	// versions of restores, that are not finished yet, cannot be deleted
+	pinned := versionsBeingRestored(actions)
+
+	now := time.Now()
+	condition := metav1.Condition{
+		Type:               ConditionRetentionApplied,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: aggregate.Generation,
+	}
+	access, accessErr := bmg.ResolveRepositoryAccess(logger, aggregate)
+	if accessErr != nil {
+		return r.delegateRetention(ctx, logger, aggregate, accessErr, pinned, backupFinishedAt, now, condition)
+	}
+
+	// on failure the plan contains only versions removed until then, the remaining ones are reported as kept
+	plan, applyErr := repository.ApplyRetention(ctx, repository.NewClient(access.URL, access.Token), access.CollectionId, *policy, pinned, now)
+	status := plan.ToRetentionStatus(policy.DryRun, now)
+	if applyErr != nil {
+		logger.Warnf("Cannot apply retention: %s", applyErr.Error())
+		condition.Reason, condition.Message = "PruningFailed", applyErr.Error()
+		r.Recorder.Event(aggregate.ScheduledBackup, "Warning", "RetentionFailed", fmt.Sprintf("Cannot apply .spec.retention: %s", applyErr.Error()))
+		return &appliedRetention{status: status, condition: condition}
+	}
+	condition.Status = metav1.ConditionTrue
+	if policy.DryRun {
+		condition.Reason = "DryRun"
+		condition.Message = fmt.Sprintf("Dry run: %d versions would be removed, %d kept", len(status.Removed), len(status.Kept))
+		r.Recorder.Event(aggregate.ScheduledBackup, "Normal", "RetentionDryRun", fmt.Sprintf("Would remove %d versions%s, keeping %d", len(status.Removed), summarizeVersions(status.Removed), len(status.Kept)))
+	} else {
+		condition.Reason = "VersionsRemoved"
+		condition.Message = fmt.Sprintf("Removed %d versions, %d kept", len(status.Removed), len(status.Kept))
+		r.Recorder.Event(aggregate.ScheduledBackup, "Normal", "RetentionApplied", fmt.Sprintf("Removed %d versions%s, keeping %d", len(status.Removed), summarizeVersions(status.Removed), len(status.Kept)))
+	}
+	logger.Info(condition.Message)
+	return &appliedRetention{status: status, condition: condition}
+}
+
+// delegateRetention is creating a Job applying the retention, when the controller cannot reach the Backup Repository itself
+func (r *JobsManagedByScheduledBackupObserver) delegateRetention(ctx context.Context, logger *logrus.Entry, aggregate *domain.ScheduledBackupAggregate, accessErr error,
+	pinned []string, backupFinishedAt time.Time, now time.Time, condition metav1.Condition) *appliedRetention {
+
+	appliedTime := metav1.NewTime(now)
+	status := &riotkitorgv1alpha1.RetentionStatus{LastAppliedTime: &appliedTime, DryRun: aggregate.Spec.Retention.DryRun}
+	if r.RetentionJobImage == "" {
+		logger.Debugf("Cannot apply retention: %s", accessErr.Error())
+		condition.Reason = reasonRepositoryNotAccessible
+		condition.Message = fmt.Sprintf("%s, and retention Jobs are disabled", accessErr.Error())
+		return &appliedRetention{status: status, condition: condition}
+	}
+	jobName, jobErr := bmg.ApplyRetentionJob(ctx, logger, r.Recorder, r.RESTMapper, r.DynClient, r.Resources, aggregate, r.RetentionJobImage, pinned, backupFinishedAt)
+	if jobErr != nil {
+		logger.Warnf("Cannot create retention Job: %s", jobErr.Error())
+		condition.Reason, condition.Message = "JobNotCreated", fmt.Sprintf("%s, and cannot create a retention Job: %s", accessErr.Error(), jobErr.Error())
+		r.Recorder.Event(aggregate.ScheduledBackup, "Warning", "RetentionFailed", condition.Message)
+		return &appliedRetention{status: status, condition: condition}
+	}
+
+	status.JobName = jobName
+	condition.Status, condition.Reason = metav1.ConditionTrue, "DelegatedToJob"
+	condition.Message = fmt.Sprintf("Backup Repository is not accessible by the controller, retention is applied by Job '%s'", jobName)
+	r.Recorder.Event(aggregate.ScheduledBackup, "Normal", "RetentionJobCreated", condition.Message)
+	return &appliedRetention{status: status, condition: condition}
+}
 
-	return ctrl.Result{}, nil
+// listActions is listing RequestedBackupActions of the ScheduledBackup
+func (r *JobsManagedByScheduledBackupObserver) listActions(ctx context.Context, backup *riotkitorgv1alpha1.ScheduledBackup) ([]riotkitorgv1alpha1.RequestedBackupAction, error) {
+	list := riotkitorgv1alpha1.RequestedBackupActionList{}
+	if err := r.Client.List(ctx, &list, client.InNamespace(backup.Namespace)); err != nil {
+		return nil, errors.Wrap(err, "cannot list RequestedBackupActions")
+	}
+	var actions []riotkitorgv1alpha1.RequestedBackupAction
+	for _, action := range list.Items {
+		if action.Spec.ScheduledBackupRef.Name == backup.Name {
+			actions = append(actions, action)
+		}
+	}
+	return actions, nil
 }
 
-func (r *JobsManagedByScheduledBackupObserver) updateStatus(ctx context.Context, logger *logrus.Entry, aggregate *domain.ScheduledBackupAggregate, report []riotkitorgv1alpha1.JobHealthStatus, healthy bool) {
-	retry.RetryOnConflict(retry.DefaultRetry, func() error {
+// versionsBeingRestored is returning versions (e.g. "v3") resolved by RequestedBackupActions, that are not finished yet.
+// An action that is going to be retried is back in the Pending phase
+func versionsBeingRestored(actions []riotkitorgv1alpha1.RequestedBackupAction) []string {
+	var pinned []string
+	for _, action := range actions {
+		if action.Status.ResolvedVersion == nil || action.Status.Phase.IsTerminal() {
+			continue
+		}
+		pinned = append(pinned, action.Status.ResolvedVersion.GetName())
+	}
+	return pinned
+}
+
+// summarizeVersions is listing first few version names, e.g. for an event message
+func summarizeVersions(names []string) string {
+	const limit = 10
+	if len(names) == 0 {
+		return ""
+	}
+	if len(names) > limit {
+		return fmt.Sprintf(": %s and %d more", strings.Join(names[:limit], ", "), len(names)-limit)
+	}
+	return ": " + strings.Join(names, ", ")
+}
+
+// observeCronJobs is collecting runs of CronJobs managed by ScheduledBackup, keyed by the schedule name. A schedule without a CronJob is skipped
+func (r *JobsManagedByScheduledBackupObserver) observeCronJobs(ctx context.Context, logger *logrus.Entry, backup *riotkitorgv1alpha1.ScheduledBackup, ownedReferences riotkitorgv1alpha1.ChildrenReferences) map[string]riotkitorgv1alpha1.CronJobStatus {
+	observed := make(map[string]riotkitorgv1alpha1.CronJobStatus)
+	for _, schedule := range backup.Spec.GetSchedules() {
+		cronJobName := schedule.GetCronJobName(backup.Name)
+		for _, ref := range ownedReferences {
+			if ref.Kind != "CronJob" || ref.Name != cronJobName {
+				continue
+			}
+			runs, err := r.Integrations.GetCronJobStatus(ctx, ref.TrackingId, backup.Namespace)
+			if err != nil {
+				logger.Warnf("Cannot collect runs of CronJob '%s': %s", cronJobName, err.Error())
+				break
+			}
+			observed[schedule.Name] = runs
+			break
+		}
+	}
+	return observed
+}
+
+func (r *JobsManagedByScheduledBackupObserver) updateStatus(ctx context.Context, logger *logrus.Entry, aggregate *domain.ScheduledBackupAggregate, report []riotkitorgv1alpha1.JobHealthStatus, healthy bool,
+	cronJobRuns map[string]riotkitorgv1alpha1.CronJobStatus, versions *listedVersions, retention *appliedRetention) *domain.BackupAgeReport {
+	var ageReport *domain.BackupAgeReport
+	var becameOverdue bool
+	var updated *riotkitorgv1alpha1.ScheduledBackup
+	var finishedRuns []scheduledRun
+
+	updateErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		res, getErr := r.BRClient.ScheduledBackups(aggregate.Namespace).Get(ctx, aggregate.Name, metav1.GetOptions{})
 		if getErr != nil {
 			return getErr
 		}
-		res.Status = aggregate.ScheduledBackup.Status
+		// .status.ownedReferences are maintained by the ScheduledBackupReconciler, the cached copy could be outdated
 		res.Status.ChildrenResourcesHealth = report
 		res.Status.Healthy = healthy
+		schedules := res.Spec.GetSchedules()
+		finishedRuns = nil
+		for _, schedule := range schedules {
+			if runs, observed := cronJobRuns[schedule.Name]; observed {
+				for _, run := range res.Status.RecordCronJobRuns(schedule, runs) {
+					finishedRuns = append(finishedRuns, scheduledRun{schedule: schedule, run: run})
+				}
+			}
+		}
+		res.Status.ForgetRemovedSchedules(schedules)
+		if versions != nil {
+			if versions.status != nil {
+				res.Status.Repository = versions.status
+			}
+			meta.SetStatusCondition(&res.Status.Conditions, versions.condition)
+		}
+		if retention != nil {
+			res.Status.Retention = retention.status
+			meta.SetStatusCondition(&res.Status.Conditions, retention.condition)
+		}
+		if res.Spec.Retention == nil {
+			res.Status.Retention = nil
+			meta.RemoveStatusCondition(&res.Status.Conditions, ConditionRetentionApplied)
+		}
+
+		// compare the newest successful backup with the schedule
+		var checkErr error
+		ageReport, checkErr = domain.CheckBackupAge(res, time.Now())
+		if checkErr != nil {
+			logger.Warnf("Cannot check if the backup is overdue: %s", checkErr.Error())
+		}
+		wasOverdue := meta.IsStatusConditionTrue(res.Status.Conditions, ConditionBackupOverdue)
+		if ageReport != nil {
+			meta.SetStatusCondition(&res.Status.Conditions, createBackupOverdueCondition(ageReport, res.Generation))
+			becameOverdue = ageReport.Overdue && !wasOverdue
+		} else {
+			meta.RemoveStatusCondition(&res.Status.Conditions, ConditionBackupOverdue)
+		}
 
-		_, updateErr := r.BRClient.ScheduledBackups(aggregate.Namespace).UpdateStatus(ctx, res, metav1.UpdateOptions{})
-		logger.Debugf(".status field updated with .ChildrenResourcesHealth and .Healthy")
+		var updateErr error
+		updated, updateErr = r.BRClient.ScheduledBackups(aggregate.Namespace).UpdateStatus(ctx, res, metav1.UpdateOptions{})
+		logger.Debugf(".status field updated with .ChildrenResourcesHealth, .Healthy, .CronJob, .Schedules, .Repository and .Retention")
 		return updateErr
 	})
+	if updateErr != nil {
+		logger.Errorf("Cannot update .status field: %s", updateErr.Error())
+		return ageReport
+	}
+	recordCronJobMetrics(updated)
+	r.notifyFinishedRuns(logger, updated, finishedRuns)
+	if becameOverdue {
+		r.Recorder.Event(aggregate.ScheduledBackup, "Warning", ConditionBackupOverdue, createBackupOverdueCondition(ageReport, aggregate.Generation).Message)
+	}
+	return ageReport
+}
+
+// recordCronJobMetrics is reporting the persisted history of CronJob runs to Prometheus. Runs of all schedules of the same operation
+// are reported together, already reported runs are skipped
+func recordCronJobMetrics(backup *riotkitorgv1alpha1.ScheduledBackup) {
+	runs := make(map[string][]metrics.Run)
+	for _, schedule := range backup.Spec.GetSchedules() {
+		runs[schedule.Operation] = append(runs[schedule.Operation], metrics.RunsFromCronJobStatus(backup.Status.GetCronJobStatus(schedule))...)
+	}
+	for operation, operationRuns := range runs {
+		metrics.RecordRuns(metrics.Labels(backup, operation), operationRuns)
+	}
+}
+
+// scheduledRun is a run of a CronJob created for given schedule
+type scheduledRun struct {
+	schedule riotkitorgv1alpha1.ScheduleSpec
+	run      riotkitorgv1alpha1.CronJobRun
+}
+
+// notifyFinishedRuns is sending notifications about runs of CronJobs, that finished since the previous observation
+func (r *JobsManagedByScheduledBackupObserver) notifyFinishedRuns(logger *logrus.Entry, backup *riotkitorgv1alpha1.ScheduledBackup, finishedRuns []scheduledRun) {
+	for _, finished := range finishedRuns {
+		sendNotification(r.Notifier, r.Recorder, logger, backup, backup.Spec.NotificationChannelRefs, notification.Notification{
+			Kind:            "ScheduledBackup",
+			Namespace:       backup.Namespace,
+			Name:            backup.Name,
+			ScheduledBackup: backup.Name,
+			Operation:       finished.schedule.Operation,
+			Outcome:         finished.run.Outcome,
+			JobName:         finished.run.JobName,
+			StartTime:       toTime(finished.run.StartTime),
+			CompletionTime:  toTime(finished.run.CompletionTime),
+		})
+	}
+}
+
+// ConditionBackupOverdue is True, when the newest successful backup is older than allowed
+const ConditionBackupOverdue = "BackupOverdue"
+
+func createBackupOverdueCondition(ageReport *domain.BackupAgeReport, generation int64) metav1.Condition {
+	lastSuccessful := "never"
+	if ageReport.LastSuccessful != nil {
+		lastSuccessful = ageReport.LastSuccessful.UTC().Format(time.RFC3339)
+	}
+	condition := metav1.Condition{
+		Type:               ConditionBackupOverdue,
+		Status:             metav1.ConditionFalse,
+		Reason:             "BackupUpToDate",
+		Message:            fmt.Sprintf("Newest successful backup: %s", lastSuccessful),
+		ObservedGeneration: generation,
+	}
+	if ageReport.Overdue {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "BackupMissed"
+		condition.Message = fmt.Sprintf("Expected a successful backup after %s, newest successful backup: %s",
+			ageReport.RequiredSince.UTC().Format(time.RFC3339), lastSuccessful)
+	}
+	return condition
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *JobsManagedByScheduledBackupObserver) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&riotkitorgv1alpha1.ScheduledBackup{}).
-		Owns(&batchv1.Job{}).
-		Owns(&batchv1.CronJob{}).
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&riotkitorgv1alpha1.ScheduledBackup{})
+
+	// children objects of enabled integrations, e.g. Jobs, CronJobs, Argo Workflows
+	for _, owned := range r.Integrations.GetOwnedObjects() {
+		builder = builder.Owns(owned)
+	}
+	// a finished manual backup is triggering the retention and listing of versions
+	builder = builder.Watches(&source.Kind{Type: &riotkitorgv1alpha1.RequestedBackupAction{}}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		action, ok := obj.(*riotkitorgv1alpha1.RequestedBackupAction)
+		if !ok || action.Spec.Action != string(domain.Backup) || !action.Status.Phase.IsTerminal() {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: action.Spec.ScheduledBackupRef.Name, Namespace: action.Namespace}}}
+	}))
+	return builder.
 		WithEventFilter(predicate.Funcs{
+			// deleted children are not interesting, but the ScheduledBackup itself has to be forgotten
 			DeleteFunc: func(e event.DeleteEvent) bool {
-				return false
+				_, isScheduledBackup := e.Object.(*riotkitorgv1alpha1.ScheduledBackup)
+				return isScheduledBackup
 			},
 		}).
 		Complete(r)
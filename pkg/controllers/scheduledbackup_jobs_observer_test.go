@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+// TestLastBackupFinishedAt is checking that backups performed manually by RequestedBackupActions are triggering the retention, same as schedules
+func TestLastBackupFinishedAt(t *testing.T) {
+	at := func(hour int) *metav1.Time {
+		finished := metav1.NewTime(time.Date(2023, 1, 1, hour, 0, 0, 0, time.UTC))
+		return &finished
+	}
+	backup := &v1alpha1.ScheduledBackup{Spec: v1alpha1.ScheduledBackupSpec{
+		Schedules: []v1alpha1.ScheduleSpec{{Name: "daily", Operation: "backup"}},
+	}}
+	cronJobRuns := map[string]v1alpha1.CronJobStatus{
+		"daily": {LastRuns: []v1alpha1.CronJobRun{{Outcome: v1alpha1.RunSucceeded, CompletionTime: at(1)}}},
+	}
+	createAction := func(action string, phase v1alpha1.RequestedBackupActionPhase, completion *metav1.Time) v1alpha1.RequestedBackupAction {
+		return v1alpha1.RequestedBackupAction{
+			Spec:   v1alpha1.RequestedBackupActionSpec{Action: action},
+			Status: v1alpha1.RequestedBackupActionStatus{Phase: phase, CompletionTime: completion},
+		}
+	}
+
+	assert.Equal(t, at(1).Time, lastBackupFinishedAt(backup, cronJobRuns, nil))
+
+	// restores and not successful backups are not uploading any version
+	assert.Equal(t, at(1).Time, lastBackupFinishedAt(backup, cronJobRuns, []v1alpha1.RequestedBackupAction{
+		createAction("restore", v1alpha1.PhaseSucceeded, at(2)),
+		createAction("backup", v1alpha1.PhaseFailed, at(3)),
+	}))
+
+	assert.Equal(t, at(4).Time, lastBackupFinishedAt(backup, cronJobRuns, []v1alpha1.RequestedBackupAction{
+		createAction("backup", v1alpha1.PhaseSucceeded, at(4)),
+	}))
+
+	// without any schedule
+	assert.Equal(t, at(4).Time, lastBackupFinishedAt(&v1alpha1.ScheduledBackup{}, nil, []v1alpha1.RequestedBackupAction{
+		createAction("backup", v1alpha1.PhaseSucceeded, at(4)),
+	}))
+}
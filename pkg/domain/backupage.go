@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// DefaultBackupGracePeriod is a time given to a scheduled backup to finish, when .spec.timeout is not specified.
+// Same as the default `activeDeadlineSeconds` in Backup Maker Generator's Helm chart
+const DefaultBackupGracePeriod = time.Hour
+
+// BackupAgeReport tells if the newest successful backup is fresh enough
+type BackupAgeReport struct {
+	// Overdue is true, when there is no successful backup made after RequiredSince
+	Overdue bool
+	// RequiredSince is the moment after which at least one successful backup should have been completed
+	RequiredSince time.Time
+	// LastSuccessful is a completion time of the newest successful backup, nil when there is none
+	LastSuccessful *time.Time
+	// NextCheck is a moment when the report could change, even if nothing happens on the cluster. Zero, when only a new backup could change it
+	NextCheck time.Time
+}
+
+// CheckBackupAge is comparing the newest successful run of backup CronJobs with the expected last run.
+// The expected last run comes from .spec.maxBackupAge, or is derived from schedules of all backup CronJobs - a backup is expected after each scheduled run.
+// Returns nil, when backups are not scheduled, so nothing could be checked
+func CheckBackupAge(backup *v1alpha1.ScheduledBackup, now time.Time) (*BackupAgeReport, error) {
+	var schedules []v1alpha1.ScheduleSpec
+	for _, schedule := range backup.Spec.GetSchedules() {
+		if Operation(schedule.Operation) == Backup {
+			schedules = append(schedules, schedule)
+		}
+	}
+	if len(schedules) == 0 {
+		return nil, nil
+	}
+
+	report := BackupAgeReport{}
+	if backup.Spec.MaxBackupAge != nil {
+		report.RequiredSince = now.Add(-backup.Spec.MaxBackupAge.Duration)
+	} else {
+		grace := DefaultBackupGracePeriod
+		if backup.Spec.Timeout != nil {
+			grace = backup.Spec.Timeout.Duration
+		}
+		// a run is expected to be finished after the grace period. Kubernetes is evaluating schedules in UTC by default
+		until := now.UTC().Add(-grace)
+		for _, schedule := range schedules {
+			parsed, err := cron.ParseStandard(schedule.ScheduleEvery)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot parse scheduleEvery '%s' of CronJob '%s'", schedule.ScheduleEvery, schedule.GetCronJobName(backup.Name))
+			}
+			if last := getLastScheduledTime(parsed, until); last.After(report.RequiredSince) {
+				report.RequiredSince = last
+			}
+			if next := parsed.Next(until).Add(grace); report.NextCheck.IsZero() || next.Before(report.NextCheck) {
+				report.NextCheck = next
+			}
+		}
+	}
+
+	// any of backup CronJobs could have made the newest backup
+	for _, schedule := range schedules {
+		status := backup.Status.GetCronJobStatus(schedule)
+		if status == nil {
+			continue
+		}
+		if last := getLastSuccessfulTime(status); last != nil && (report.LastSuccessful == nil || last.After(*report.LastSuccessful)) {
+			report.LastSuccessful = last
+		}
+	}
+	if backup.Spec.MaxBackupAge != nil {
+		// the newest backup (or the ScheduledBackup itself) gets too old
+		since := backup.CreationTimestamp.Time
+		if report.LastSuccessful != nil && report.LastSuccessful.After(since) {
+			since = *report.LastSuccessful
+		}
+		if expiry := since.Add(backup.Spec.MaxBackupAge.Duration); expiry.After(now) {
+			report.NextCheck = expiry
+		}
+	}
+	// nothing could be done before the ScheduledBackup was created
+	if report.RequiredSince.Before(backup.CreationTimestamp.Time) {
+		return &report, nil
+	}
+	report.Overdue = report.LastSuccessful == nil || report.LastSuccessful.Before(report.RequiredSince)
+	return &report, nil
+}
+
+// getLastScheduledTime is returning the latest moment, not later than `until`, matching the schedule
+func getLastScheduledTime(schedule cron.Schedule, until time.Time) time.Time {
+	// look back far enough to find at least one scheduled run
+	lookBack := time.Minute
+	for schedule.Next(until.Add(-lookBack)).After(until) && lookBack < time.Hour*24*366*5 {
+		lookBack *= 2
+	}
+
+	last := time.Time{}
+	for next := schedule.Next(until.Add(-lookBack)); !next.IsZero() && !next.After(until); next = schedule.Next(next) {
+		last = next
+	}
+	return last
+}
+
+// getLastSuccessfulTime is returning the completion time of the newest successful run known to the CronJob or to the history
+func getLastSuccessfulTime(status *v1alpha1.CronJobStatus) *time.Time {
+	var last *time.Time
+	if status.LastSuccessfulTime != nil {
+		last = &status.LastSuccessfulTime.Time
+	}
+	for _, run := range status.LastRuns {
+		if run.Outcome != v1alpha1.RunSucceeded || run.CompletionTime == nil {
+			continue
+		}
+		if last == nil || run.CompletionTime.After(*last) {
+			completed := run.CompletionTime.Time
+			last = &completed
+		}
+	}
+	return last
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func createScheduledBackupForAgeCheck(lastSuccessful *time.Time) *v1alpha1.ScheduledBackup {
+	backup := &v1alpha1.ScheduledBackup{
+		ObjectMeta: v1.ObjectMeta{CreationTimestamp: v1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))},
+		Spec: v1alpha1.ScheduledBackupSpec{
+			Operation: "backup",
+			CronJob:   v1alpha1.CronJobSpec{Enabled: true, ScheduleEvery: "00 02 * * *"},
+		},
+	}
+	if lastSuccessful != nil {
+		backup.Status.CronJob = &v1alpha1.CronJobStatus{LastSuccessfulTime: &v1.Time{Time: *lastSuccessful}}
+	}
+	return backup
+}
+
+// TestCheckBackupAge_DerivedFromSchedule is checking that a backup is expected after each scheduled run, once the grace period passes
+func TestCheckBackupAge_DerivedFromSchedule(t *testing.T) {
+	yesterdayBackup := time.Date(2023, 1, 9, 2, 10, 0, 0, time.UTC)
+	backup := createScheduledBackupForAgeCheck(&yesterdayBackup)
+
+	// today's run at 02:00 still has time to finish (1 hour by default)
+	report, err := CheckBackupAge(backup, time.Date(2023, 1, 10, 2, 30, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.False(t, report.Overdue)
+	assert.Equal(t, time.Date(2023, 1, 9, 2, 0, 0, 0, time.UTC), report.RequiredSince)
+	assert.Equal(t, time.Date(2023, 1, 10, 3, 0, 0, 0, time.UTC), report.NextCheck)
+
+	// today's run did not succeed
+	report, _ = CheckBackupAge(backup, time.Date(2023, 1, 10, 3, 30, 0, 0, time.UTC))
+	assert.True(t, report.Overdue)
+	assert.Equal(t, time.Date(2023, 1, 10, 2, 0, 0, 0, time.UTC), report.RequiredSince)
+
+	// a custom timeout is giving more time to finish
+	backup.Spec.Timeout = &v1.Duration{Duration: time.Hour * 2}
+	report, _ = CheckBackupAge(backup, time.Date(2023, 1, 10, 3, 30, 0, 0, time.UTC))
+	assert.False(t, report.Overdue)
+}
+
+// TestCheckBackupAge_MaxBackupAge is checking that explicit .spec.maxBackupAge has priority over the schedule
+func TestCheckBackupAge_MaxBackupAge(t *testing.T) {
+	lastBackup := time.Date(2023, 1, 9, 2, 10, 0, 0, time.UTC)
+	backup := createScheduledBackupForAgeCheck(&lastBackup)
+	backup.Spec.MaxBackupAge = &v1.Duration{Duration: time.Hour * 72}
+
+	report, _ := CheckBackupAge(backup, time.Date(2023, 1, 11, 12, 0, 0, 0, time.UTC))
+	assert.False(t, report.Overdue)
+	assert.Equal(t, lastBackup.Add(time.Hour*72), report.NextCheck)
+
+	report, _ = CheckBackupAge(backup, time.Date(2023, 1, 12, 3, 0, 0, 0, time.UTC))
+	assert.True(t, report.Overdue)
+	assert.True(t, report.NextCheck.IsZero())
+}
+
+// TestCheckBackupAge_NeverSucceeded is checking that a fresh ScheduledBackup is not overdue, but a one that never succeeded is
+func TestCheckBackupAge_NeverSucceeded(t *testing.T) {
+	backup := createScheduledBackupForAgeCheck(nil)
+
+	report, _ := CheckBackupAge(backup, time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC))
+	assert.False(t, report.Overdue)
+
+	report, _ = CheckBackupAge(backup, time.Date(2023, 1, 1, 4, 0, 0, 0, time.UTC))
+	assert.True(t, report.Overdue)
+	assert.Nil(t, report.LastSuccessful)
+
+	// nothing is scheduled
+	backup.Spec.CronJob.Enabled = false
+	report, _ = CheckBackupAge(backup, time.Date(2023, 1, 1, 4, 0, 0, 0, time.UTC))
+	assert.Nil(t, report)
+}
+
+// TestCheckBackupAge_MultipleSchedules is checking that a backup is expected after a run of any backup schedule, while restores are not counted
+func TestCheckBackupAge_MultipleSchedules(t *testing.T) {
+	morningBackup := time.Date(2023, 1, 10, 2, 10, 0, 0, time.UTC)
+	backup := createScheduledBackupForAgeCheck(&morningBackup)
+	backup.Spec.Schedules = []v1alpha1.ScheduleSpec{
+		{Name: "noon", Operation: "backup", ScheduleEvery: "00 12 * * *"},
+		{Name: "verify", Operation: "restore", ScheduleEvery: "30 * * * *"},
+	}
+
+	report, err := CheckBackupAge(backup, time.Date(2023, 1, 10, 2, 30, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.False(t, report.Overdue)
+	assert.Equal(t, time.Date(2023, 1, 10, 3, 0, 0, 0, time.UTC), report.NextCheck, "Expected the nearest check of all backup schedules")
+
+	// the noon backup did not succeed
+	report, _ = CheckBackupAge(backup, time.Date(2023, 1, 10, 13, 30, 0, 0, time.UTC))
+	assert.True(t, report.Overdue)
+	assert.Equal(t, time.Date(2023, 1, 10, 12, 0, 0, 0, time.UTC), report.RequiredSince)
+
+	// the noon backup succeeded
+	backup.Status.RecordCronJobRuns(backup.Spec.Schedules[0], v1alpha1.CronJobStatus{
+		LastSuccessfulTime: &v1.Time{Time: time.Date(2023, 1, 10, 12, 10, 0, 0, time.UTC)},
+	})
+	report, _ = CheckBackupAge(backup, time.Date(2023, 1, 10, 13, 30, 0, 0, time.UTC))
+	assert.False(t, report.Overdue)
+
+	// only restores are scheduled
+	backup.Spec.CronJob.Enabled = false
+	backup.Spec.Schedules = backup.Spec.Schedules[1:]
+	report, _ = CheckBackupAge(backup, time.Date(2023, 1, 10, 13, 30, 0, 0, time.UTC))
+	assert.Nil(t, report)
+}
@@ -14,8 +14,16 @@ type Renderable interface {
 	GetBackupAggregate() *ScheduledBackupAggregate
 	GetObjectForOwnerReference() KubernetesResource
 	AddOwnedObject(doc *unstructured.Unstructured)
+	GetReferencesOfOwnedObjects() v1alpha1.ChildrenReferences
 	ShouldRenderDependentObjectsForAllOperationTypes() bool
-	ShouldCreateCronJob() bool
+	ShouldRenderDependentObjectsForOwnOperation() bool
+	GetTargetNamespace() string
+	GetResourceName() string
+	GetSchedules() []v1alpha1.ScheduleSpec
+	GetSchedule() *v1alpha1.ScheduleSpec
+	GetTimeout() *v1.Duration
+	// GetExtraEnv are environment variables added to the container performing the operation
+	GetExtraEnv() map[string]string
 }
 
 type KubernetesResource interface {
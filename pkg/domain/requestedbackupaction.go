@@ -5,6 +5,7 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"time"
 )
 
 // RequestedBackupActionAggregate is aggregating already hydrated (fetched from cache/cluster) objects all together
@@ -12,6 +13,9 @@ type RequestedBackupActionAggregate struct {
 	*v1alpha1.RequestedBackupAction
 
 	Scheduled *ScheduledBackupAggregate
+
+	// RunnableKinds are kinds of integrations enabled in the controller, that can perform the action
+	RunnableKinds []schema.GroupVersionKind
 }
 
 func (a *RequestedBackupActionAggregate) MarkAsProcessed() {
@@ -22,40 +26,188 @@ func (a RequestedBackupActionAggregate) WasAlreadyProcessed() bool {
 	return a.Status.Processed
 }
 
+// MarkAsPending is moving the action back to the Pending phase e.g. when its dependencies are not ready yet
+func (a *RequestedBackupActionAggregate) MarkAsPending() {
+	a.Status.Phase = v1alpha1.PhasePending
+}
+
+// MarkAsRendering is marking, that the objects are being templated and applied to the cluster
+func (a *RequestedBackupActionAggregate) MarkAsRendering() {
+	a.Status.Phase = v1alpha1.PhaseRendering
+}
+
+// MarkAsStarted is called, when the Job was applied to the cluster. Every call is a next attempt
+func (a *RequestedBackupActionAggregate) MarkAsStarted(now v1.Time) {
+	a.Status.Phase = v1alpha1.PhaseRunning
+	if a.Status.StartTime == nil {
+		a.Status.StartTime = &now
+	}
+	a.Status.CompletionTime = nil
+	a.Status.Attempts += 1
+
+	attempt := v1alpha1.ActionAttempt{Attempt: a.Status.Attempts, Phase: v1alpha1.PhaseRunning, StartTime: &now}
+	if spawned := a.getSpawnedReference(); spawned != nil {
+		attempt.TrackingId = spawned.TrackingId
+		attempt.JobName = spawned.Name
+	}
+	a.Status.JobName = attempt.JobName
+	a.Status.History = append(a.Status.History, attempt)
+}
+
+// UpdatePhaseFromHealthReport is moving the action through Running to a terminal phase, basing on the health of spawned objects.
+// Returns true, when the action has just reached a terminal phase
+func (a *RequestedBackupActionAggregate) UpdatePhaseFromHealthReport(report []v1alpha1.JobHealthStatus, now v1.Time) bool {
+	if a.Status.Phase.IsTerminal() || len(report) == 0 {
+		return false
+	}
+
+	phase := v1alpha1.PhaseSucceeded
+	for _, status := range report {
+		if status.Name != "" {
+			a.Status.JobName = status.Name
+		}
+		if status.Failed {
+			phase = v1alpha1.PhaseFailed
+			if status.TimedOut {
+				phase = v1alpha1.PhaseTimedOut
+			}
+			break
+		}
+		if status.Running {
+			phase = v1alpha1.PhaseRunning
+		}
+	}
+
+	a.Status.Phase = phase
+	if a.Status.StartTime == nil {
+		a.Status.StartTime = &now
+	}
+	attempt := a.getCurrentAttempt()
+	if attempt != nil {
+		attempt.Phase = phase
+		attempt.JobName = a.Status.JobName
+	}
+	if !phase.IsTerminal() {
+		return false
+	}
+	a.Status.CompletionTime = &now
+	if attempt != nil {
+		attempt.CompletionTime = &now
+	}
+	return true
+}
+
+// IsDeadlineExceeded tells if the current attempt is running longer than the timeout allows
+func (a *RequestedBackupActionAggregate) IsDeadlineExceeded(now time.Time) bool {
+	timeout := a.GetTimeout()
+	attempt := a.getCurrentAttempt()
+	if a.Status.Phase != v1alpha1.PhaseRunning || timeout == nil || attempt == nil || attempt.StartTime == nil {
+		return false
+	}
+	return now.Sub(attempt.StartTime.Time) > timeout.Duration
+}
+
+// MarkAsTimedOut is finishing the current attempt, that was killed after exceeding the timeout
+func (a *RequestedBackupActionAggregate) MarkAsTimedOut(now v1.Time) {
+	a.Status.Phase = v1alpha1.PhaseTimedOut
+	a.Status.CompletionTime = &now
+	if attempt := a.getCurrentAttempt(); attempt != nil {
+		attempt.Phase = v1alpha1.PhaseTimedOut
+		attempt.CompletionTime = &now
+	}
+}
+
+// CanRetry tells if the finished attempt should be repeated in a fresh Job, according to .spec.retryPolicy
+func (a RequestedBackupActionAggregate) CanRetry() bool {
+	policy := a.Spec.RetryPolicy
+	return policy != nil && a.Status.Attempts < policy.MaxAttempts && policy.AllowsRetryOn(a.Status.Phase)
+}
+
+// ScheduleRetry is bringing the action back to the Pending phase, so the reconciler would create a fresh Job after the backoff.
+// The previous attempt stays in the .status.history
+func (a *RequestedBackupActionAggregate) ScheduleRetry() {
+	a.Status.Phase = v1alpha1.PhasePending
+	a.Status.Processed = false
+	a.Status.CompletionTime = nil
+	a.Status.OwnedReferences = nil
+}
+
+// GetRetryDelay is returning how long to wait until the next attempt could be started
+func (a RequestedBackupActionAggregate) GetRetryDelay(now time.Time) time.Duration {
+	attempt := a.getCurrentAttempt()
+	if a.Spec.RetryPolicy == nil || attempt == nil || attempt.CompletionTime == nil {
+		return 0
+	}
+	remaining := attempt.CompletionTime.Add(a.Spec.RetryPolicy.BackoffFor(a.Status.Attempts + 1)).Sub(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// GetSpawnedJobName is returning a name of the most recently created object, that performs the action
+func (a RequestedBackupActionAggregate) GetSpawnedJobName() string {
+	if spawned := a.getSpawnedReference(); spawned != nil {
+		return spawned.Name
+	}
+	return ""
+}
+
+func (a RequestedBackupActionAggregate) getSpawnedReference() *v1alpha1.ChildReference {
+	refs := a.Status.OwnedReferences
+	for i := len(refs) - 1; i >= 0; i-- {
+		if refs[i].Name != "" {
+			return &refs[i]
+		}
+	}
+	return nil
+}
+
+func (a *RequestedBackupActionAggregate) getCurrentAttempt() *v1alpha1.ActionAttempt {
+	if len(a.Status.History) == 0 {
+		return nil
+	}
+	return &a.Status.History[len(a.Status.History)-1]
+}
+
 func (a RequestedBackupActionAggregate) GetReferencesOfOwnedObjects() v1alpha1.ChildrenReferences {
 	return a.RequestedBackupAction.Status.OwnedReferences
 }
 
-// todo: move to integration package
+// AcceptedResourceTypes are runnable kinds of enabled integrations. A restore into a different target accepts also its own helper objects
 func (a RequestedBackupActionAggregate) AcceptedResourceTypes() []v1.GroupVersionKind {
-	return []v1.GroupVersionKind{
-		{Group: "batch", Version: "v1", Kind: "Job"},
-		{Group: "", Version: "v1", Kind: "Pod"},
-
-		//// Tekton Pipelines v1alpha1
-		//{Group: "tekton.dev", Version: "v1alpha1", Kind: "PipelineRun"},
-		//{Group: "tekton.dev", Version: "v1alpha1", Kind: "TaskRun"},
-		//{Group: "tekton.dev", Version: "v1alpha1", Kind: "Pipeline"},
-		//{Group: "tekton.dev", Version: "v1alpha1", Kind: "Task"},
-		//
-		//// Tekton Pipelines v1
-		//{Group: "tekton.dev", Version: "v1", Kind: "PipelineRun"},
-		//{Group: "tekton.dev", Version: "v1", Kind: "TaskRun"},
-		//{Group: "tekton.dev", Version: "v1", Kind: "Pipeline"},
-		//{Group: "tekton.dev", Version: "v1", Kind: "Task"},
-		//
-		//// Argo Workflows
-		//{Group: "argoproj.io", Version: "v1alpha1", Kind: "Workflow"},
-		//{Group: "argoproj.io", Version: "v1alpha1", Kind: "WorkflowTemplate"},
+	if a.ShouldRenderDependentObjectsForOwnOperation() {
+		accepted := NewResourceTypesFilterForRestoreTarget(a.RunnableKinds)
+		return accepted.GetKinds()
 	}
+	runnable := NewResourceTypesFilterForRequestedBackupAction(a.RunnableKinds)
+	return runnable.GetKinds()
+}
+
+// IsKindTypeRunnable tells if .spec.kindType can be performed by one of enabled integrations
+func (a RequestedBackupActionAggregate) IsKindTypeRunnable() bool {
+	return IsRunnableKindType(a.RunnableKinds, a.Spec.KindType)
 }
 
 func (a RequestedBackupActionAggregate) GetOperation() Operation {
 	return Operation(a.Spec.Action)
 }
 
-func (sb RequestedBackupActionAggregate) ShouldCreateCronJob() bool {
-	return false
+// GetSchedules is always empty - RequestedBackupAction is a single, manually triggered run
+func (a RequestedBackupActionAggregate) GetSchedules() []v1alpha1.ScheduleSpec {
+	return nil
+}
+
+func (a RequestedBackupActionAggregate) GetSchedule() *v1alpha1.ScheduleSpec {
+	return nil
+}
+
+// GetTimeout is returning a maximum duration of a single attempt - own, or inherited from ScheduledBackup
+func (a RequestedBackupActionAggregate) GetTimeout() *v1.Duration {
+	if a.Spec.Timeout != nil {
+		return a.Spec.Timeout
+	}
+	return a.Scheduled.GetTimeout()
 }
 
 func (a RequestedBackupActionAggregate) GetTemplate() Template {
@@ -80,13 +232,71 @@ func (a RequestedBackupActionAggregate) GetObjectForOwnerReference() KubernetesR
 
 // AddOwnedObject is adding a child element
 func (a *RequestedBackupActionAggregate) AddOwnedObject(doc *unstructured.Unstructured) {
-	v1alpha1.AddOwnedObject(&a.Status.OwnedReferences, doc)
+	v1alpha1.AddOwnedObject(&a.Status.OwnedReferences, doc, a.Namespace)
 }
 
 func (a *RequestedBackupActionAggregate) ShouldRenderDependentObjectsForAllOperationTypes() bool {
 	return false
 }
 
+// ShouldRenderDependentObjectsForOwnOperation tells, that a restore into a different target cannot use Secrets and ConfigMaps rendered by ScheduledBackup -
+// those are in a different namespace, or are containing different vars
+func (a *RequestedBackupActionAggregate) ShouldRenderDependentObjectsForOwnOperation() bool {
+	return a.HasRestoreTarget()
+}
+
+// HasRestoreTarget tells if the backup is restored somewhere else than it was made
+func (a RequestedBackupActionAggregate) HasRestoreTarget() bool {
+	return a.Spec.RestoreTarget != nil && a.GetOperation() == Restore
+}
+
+// RestoreVersionEnv is read by restore scripts of Backup Maker Generator templates, "latest" is used when not set
+const RestoreVersionEnv = "RESTORE_VERSION"
+
+// GetExtraEnv is passing the version resolved from .spec.targetVersion to the restore script. When the version could not be resolved,
+// then .spec.targetVersion is passed as-is
+func (a RequestedBackupActionAggregate) GetExtraEnv() map[string]string {
+	if a.GetOperation() != Restore {
+		return nil
+	}
+	if a.Status.ResolvedVersion != nil {
+		return map[string]string{RestoreVersionEnv: a.Status.ResolvedVersion.GetName()}
+	}
+	if a.Spec.TargetVersion != "" {
+		return map[string]string{RestoreVersionEnv: a.Spec.TargetVersion}
+	}
+	return nil
+}
+
+// MarkAsResolved is recording the version selected by .spec.targetVersion, so all attempts are restoring the same version
+func (a *RequestedBackupActionAggregate) MarkAsResolved(version v1alpha1.BackupVersion) {
+	a.Status.ResolvedVersion = &version
+}
+
+// MarkAsRejected is finishing the action without spawning a Job, e.g. when the selected version does not exist
+func (a *RequestedBackupActionAggregate) MarkAsRejected(now v1.Time) {
+	a.Status.Phase = v1alpha1.PhaseFailed
+	a.Status.Processed = true
+	a.Status.CompletionTime = &now
+}
+
+// GetTargetNamespace is returning the namespace of .spec.restoreTarget, or the namespace of ScheduledBackup
+func (a RequestedBackupActionAggregate) GetTargetNamespace() string {
+	if a.HasRestoreTarget() {
+		return a.Spec.RestoreTarget.GetNamespace(a.Namespace)
+	}
+	return a.Scheduled.Namespace
+}
+
+// GetResourceName is a base name of rendered objects. Objects rendered for a restore target are named after the RequestedBackupAction,
+// so those are not colliding with objects of a ScheduledBackup of the same name, e.g. in a staging namespace
+func (a RequestedBackupActionAggregate) GetResourceName() string {
+	if a.HasRestoreTarget() {
+		return a.Name
+	}
+	return a.Scheduled.Name
+}
+
 func NewRequestedBackupActionAggregate(action *v1alpha1.RequestedBackupAction, scheduled *ScheduledBackupAggregate) *RequestedBackupActionAggregate {
 	aggregate := RequestedBackupActionAggregate{}
 	aggregate.RequestedBackupAction = action
@@ -97,11 +307,7 @@ func NewRequestedBackupActionAggregate(action *v1alpha1.RequestedBackupAction, s
 		Kind:    "RequestedBackupAction",
 	})
 
-	targetKind := "Job"
-	if action.Spec.KindType != "" {
-		targetKind = action.Spec.KindType
-	}
-	aggregate.SetTargetKindType(targetKind)
+	aggregate.SetTargetKindType(action.Spec.KindType)
 
 	return &aggregate
 }
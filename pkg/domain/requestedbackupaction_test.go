@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func newTestRBAAggregate() *RequestedBackupActionAggregate {
+	return &RequestedBackupActionAggregate{
+		RequestedBackupAction: &v1alpha1.RequestedBackupAction{
+			ObjectMeta: v1.ObjectMeta{Name: "restore-1", Namespace: "team-a"},
+			Spec:       v1alpha1.RequestedBackupActionSpec{Action: "restore"},
+			Status: v1alpha1.RequestedBackupActionStatus{
+				OwnedReferences: v1alpha1.ChildrenReferences{
+					{APIVersion: "batch/v1", Kind: "Job", TrackingId: "aaa", Name: "app1-restore"},
+				},
+			},
+		},
+	}
+}
+
+// TestRequestedBackupActionAggregate_Lifecycle is checking the happy path: Rendering -> Running -> Succeeded
+func TestRequestedBackupActionAggregate_Lifecycle(t *testing.T) {
+	aggregate := newTestRBAAggregate()
+	started := v1.NewTime(time.Date(2023, 1, 1, 2, 0, 0, 0, time.UTC))
+	finished := v1.NewTime(started.Add(time.Minute * 5))
+
+	aggregate.MarkAsRendering()
+	assert.Equal(t, v1alpha1.PhaseRendering, aggregate.Status.Phase)
+
+	aggregate.MarkAsStarted(started)
+	assert.Equal(t, v1alpha1.PhaseRunning, aggregate.Status.Phase)
+	assert.Equal(t, 1, aggregate.Status.Attempts)
+	assert.Equal(t, "app1-restore", aggregate.Status.JobName)
+	assert.Equal(t, started, *aggregate.Status.StartTime)
+
+	// still running
+	assert.False(t, aggregate.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{{Running: true}}, finished))
+	assert.Equal(t, v1alpha1.PhaseRunning, aggregate.Status.Phase)
+	assert.Nil(t, aggregate.Status.CompletionTime)
+
+	// finished
+	assert.True(t, aggregate.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{{Succeeded: true}}, finished))
+	assert.Equal(t, v1alpha1.PhaseSucceeded, aggregate.Status.Phase)
+	assert.Equal(t, finished, *aggregate.Status.CompletionTime)
+	assert.Equal(t, started, *aggregate.Status.StartTime)
+
+	// terminal phase does not change anymore
+	assert.False(t, aggregate.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{{Failed: true}}, finished))
+	assert.Equal(t, v1alpha1.PhaseSucceeded, aggregate.Status.Phase)
+}
+
+// TestRequestedBackupActionAggregate_UpdatePhaseFromHealthReport_Failures is checking that a failure or a timeout of any child is failing the action
+func TestRequestedBackupActionAggregate_UpdatePhaseFromHealthReport_Failures(t *testing.T) {
+	now := v1.Now()
+
+	failed := newTestRBAAggregate()
+	assert.True(t, failed.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{
+		{Running: true},
+		{Failed: true, ChildReference: v1alpha1.ChildReference{Name: "app1-restore-xyz"}},
+	}, now))
+	assert.Equal(t, v1alpha1.PhaseFailed, failed.Status.Phase)
+	assert.Equal(t, "app1-restore-xyz", failed.Status.JobName)
+	assert.NotNil(t, failed.Status.StartTime)
+
+	timedOut := newTestRBAAggregate()
+	assert.True(t, timedOut.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{{Failed: true, TimedOut: true}}, now))
+	assert.Equal(t, v1alpha1.PhaseTimedOut, timedOut.Status.Phase)
+
+	// nothing reported yet
+	pending := newTestRBAAggregate()
+	assert.False(t, pending.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{}, now))
+	assert.Equal(t, v1alpha1.RequestedBackupActionPhase(""), pending.Status.Phase)
+}
+
+// TestRequestedBackupActionAggregate_Retry is checking that a failed attempt is retried with an exponential backoff, until attempts are exhausted
+func TestRequestedBackupActionAggregate_Retry(t *testing.T) {
+	aggregate := newTestRBAAggregate()
+	aggregate.Spec.RetryPolicy = &v1alpha1.RetryPolicySpec{MaxAttempts: 3}
+	aggregate.Spec.Default()
+	now := v1.NewTime(time.Date(2023, 1, 1, 2, 0, 0, 0, time.UTC))
+
+	// first attempt fails
+	aggregate.MarkAsStarted(now)
+	assert.True(t, aggregate.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{{Failed: true}}, now))
+	assert.True(t, aggregate.CanRetry())
+	aggregate.ScheduleRetry()
+
+	assert.Equal(t, v1alpha1.PhasePending, aggregate.Status.Phase)
+	assert.False(t, aggregate.WasAlreadyProcessed())
+	assert.Empty(t, aggregate.Status.OwnedReferences)
+	assert.Equal(t, time.Second*30, aggregate.GetRetryDelay(now.Time))
+	assert.Equal(t, time.Duration(0), aggregate.GetRetryDelay(now.Add(time.Minute)))
+
+	// second attempt is timing out, its backoff is doubled for the third attempt
+	aggregate.Status.OwnedReferences = v1alpha1.ChildrenReferences{{APIVersion: "batch/v1", Kind: "Job", TrackingId: "bbb", Name: "app1-restore-2"}}
+	aggregate.MarkAsStarted(now)
+	assert.True(t, aggregate.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{{Failed: true, TimedOut: true}}, now))
+	assert.True(t, aggregate.CanRetry())
+	aggregate.ScheduleRetry()
+	assert.Equal(t, time.Minute, aggregate.GetRetryDelay(now.Time))
+
+	// third attempt is the last one
+	aggregate.MarkAsStarted(now)
+	assert.True(t, aggregate.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{{Failed: true}}, now))
+	assert.False(t, aggregate.CanRetry())
+
+	assert.Len(t, aggregate.Status.History, 3)
+	assert.Equal(t, v1alpha1.ActionAttempt{Attempt: 1, TrackingId: "aaa", JobName: "app1-restore", Phase: v1alpha1.PhaseFailed, StartTime: &now, CompletionTime: &now}, aggregate.Status.History[0])
+	assert.Equal(t, "bbb", aggregate.Status.History[1].TrackingId)
+	assert.Equal(t, v1alpha1.PhaseTimedOut, aggregate.Status.History[1].Phase)
+}
+
+// TestRequestedBackupActionAggregate_Retry_OnlyOn is checking that only selected outcomes are retried
+func TestRequestedBackupActionAggregate_Retry_OnlyOn(t *testing.T) {
+	aggregate := newTestRBAAggregate()
+	aggregate.Spec.RetryPolicy = &v1alpha1.RetryPolicySpec{MaxAttempts: 3, OnlyOn: []v1alpha1.RequestedBackupActionPhase{v1alpha1.PhaseTimedOut}}
+
+	aggregate.MarkAsStarted(v1.Now())
+	aggregate.UpdatePhaseFromHealthReport([]v1alpha1.JobHealthStatus{{Failed: true}}, v1.Now())
+	assert.False(t, aggregate.CanRetry())
+}
+
+// TestRequestedBackupActionAggregate_IsDeadlineExceeded is checking that own timeout has priority over the timeout of ScheduledBackup
+func TestRequestedBackupActionAggregate_IsDeadlineExceeded(t *testing.T) {
+	aggregate := newTestRBAAggregate()
+	aggregate.Scheduled = &ScheduledBackupAggregate{ScheduledBackup: &v1alpha1.ScheduledBackup{
+		Spec: v1alpha1.ScheduledBackupSpec{Timeout: &v1.Duration{Duration: time.Hour}},
+	}}
+	started := v1.NewTime(time.Date(2023, 1, 1, 2, 0, 0, 0, time.UTC))
+	aggregate.MarkAsStarted(started)
+
+	// inherited from ScheduledBackup
+	assert.False(t, aggregate.IsDeadlineExceeded(started.Add(time.Minute*30)))
+	assert.True(t, aggregate.IsDeadlineExceeded(started.Add(time.Minute*61)))
+
+	// own
+	aggregate.Spec.Timeout = &v1.Duration{Duration: time.Minute * 10}
+	assert.True(t, aggregate.IsDeadlineExceeded(started.Add(time.Minute*30)))
+
+	aggregate.MarkAsTimedOut(v1.NewTime(started.Add(time.Minute * 30)))
+	assert.Equal(t, v1alpha1.PhaseTimedOut, aggregate.Status.Phase)
+	assert.Equal(t, v1alpha1.PhaseTimedOut, aggregate.Status.History[0].Phase)
+	assert.False(t, aggregate.IsDeadlineExceeded(started.Add(time.Minute*30)), "Expected that finished action is not timing out again")
+}
+
+func TestRequestedBackupActionAggregate_RestoreTarget(t *testing.T) {
+	aggregate := newTestRBAAggregate()
+	aggregate.Scheduled = &ScheduledBackupAggregate{ScheduledBackup: &v1alpha1.ScheduledBackup{
+		ObjectMeta: v1.ObjectMeta{Name: "app1", Namespace: "team-a"},
+	}}
+
+	// without a target the objects of ScheduledBackup are reused
+	assert.False(t, aggregate.HasRestoreTarget())
+	assert.Equal(t, "team-a", aggregate.GetTargetNamespace())
+	assert.Equal(t, "app1", aggregate.GetResourceName())
+
+	// target without a namespace is the namespace of the action
+	aggregate.Spec.RestoreTarget = &v1alpha1.RestoreTargetSpec{}
+	assert.True(t, aggregate.HasRestoreTarget())
+	assert.True(t, aggregate.ShouldRenderDependentObjectsForOwnOperation())
+	assert.Equal(t, "team-a", aggregate.GetTargetNamespace())
+	assert.Equal(t, "restore-1", aggregate.GetResourceName())
+
+	aggregate.Spec.RestoreTarget.Namespace = "staging"
+	assert.Equal(t, "staging", aggregate.GetTargetNamespace())
+
+	// only restore could have a target
+	aggregate.Spec.Action = "backup"
+	assert.False(t, aggregate.HasRestoreTarget())
+	assert.Equal(t, "team-a", aggregate.GetTargetNamespace())
+}
+
+func TestRequestedBackupActionAggregate_TargetVersion(t *testing.T) {
+	aggregate := newTestRBAAggregate()
+	assert.Nil(t, aggregate.GetExtraEnv(), "Expected that the restore script picks the latest version by itself")
+
+	// not resolved version is passed as-is
+	aggregate.Spec.TargetVersion = "v2"
+	assert.Equal(t, map[string]string{RestoreVersionEnv: "v2"}, aggregate.GetExtraEnv())
+
+	aggregate.Spec.TargetVersion = "previous:1"
+	aggregate.MarkAsResolved(v1alpha1.BackupVersion{Id: "bbb", Number: 2})
+	assert.Equal(t, map[string]string{RestoreVersionEnv: "v2"}, aggregate.GetExtraEnv())
+
+	// a retry is restoring the same version
+	aggregate.ScheduleRetry()
+	assert.Equal(t, 2, aggregate.Status.ResolvedVersion.Number)
+
+	aggregate.Spec.Action = "backup"
+	assert.Nil(t, aggregate.GetExtraEnv())
+}
+
+func TestRequestedBackupActionAggregate_MarkAsRejected(t *testing.T) {
+	aggregate := newTestRBAAggregate()
+	now := v1.NewTime(time.Date(2023, 1, 1, 2, 0, 0, 0, time.UTC))
+
+	aggregate.MarkAsRejected(now)
+
+	assert.Equal(t, v1alpha1.PhaseFailed, aggregate.Status.Phase)
+	assert.True(t, aggregate.WasAlreadyProcessed())
+	assert.Equal(t, now, *aggregate.Status.CompletionTime)
+	assert.Equal(t, 0, aggregate.Status.Attempts)
+	assert.False(t, aggregate.CanRetry())
+}
@@ -5,12 +5,20 @@ package domain
 // CRD - "ScheduledBackup" or "RequestedBackupAction"
 //
 
-import v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
 
 type ResourceTypes struct {
 	gvk []v1.GroupVersionKind
 }
 
+// NewResourceTypes is accepting given kinds, e.g. Renderable.AcceptedResourceTypes()
+func NewResourceTypes(gvk []v1.GroupVersionKind) ResourceTypes {
+	return ResourceTypes{gvk: gvk}
+}
+
 func (rt *ResourceTypes) GetKinds() []v1.GroupVersionKind {
 	return rt.gvk
 }
@@ -22,33 +30,65 @@ func (rt *ResourceTypes) GetKinds() []v1.GroupVersionKind {
 //
 //	The runnable Jobs are maintained by RequestedBackupAction
 //
-// The ScheduledBackup is optionally managing CronJobs, which are spawning Jobs according to how Kubernetes works by default
-func NewResourceTypesFilterForScheduledBackup(resource Renderable, op Operation) ResourceTypes {
-	// Helper kinds
-	gvk := []v1.GroupVersionKind{
-		{Group: "", Version: "v1", Kind: "Secret"},
-		{Group: "", Version: "v1", Kind: "ConfigMap"},
-		{Group: "bitnami.com", Version: "v1alpha1", Kind: "SealedSecret"},
-		{Group: "kubernetes-client.io", Version: "v1", Kind: "ExternalSecret"},
+// The ScheduledBackup is optionally managing CronJobs, which are spawning Jobs according to how Kubernetes works by default.
+// Those are rendered separately for each schedule, see NewResourceTypesFilterForSchedule
+func NewResourceTypesFilterForScheduledBackup() ResourceTypes {
+	return ResourceTypes{
+		gvk: []v1.GroupVersionKind{
+			{Group: "", Version: "v1", Kind: "Secret"},
+			{Group: "", Version: "v1", Kind: "ConfigMap"},
+			{Group: "bitnami.com", Version: "v1alpha1", Kind: "SealedSecret"},
+			{Group: "kubernetes-client.io", Version: "v1", Kind: "ExternalSecret"},
+		},
 	}
+}
 
-	// Case: Create target CronJob for desired action for that ScheduledBackup
-	if resource.GetOperation() == op && resource.ShouldCreateCronJob() {
-		gvk = append(gvk, v1.GroupVersionKind{
-			Group: "batch", Version: "v1", Kind: "CronJob",
-		})
+// NewResourceTypesFilterForSchedule decides that a single schedule of ScheduledBackup is rendering only its CronJob
+func NewResourceTypesFilterForSchedule() ResourceTypes {
+	return ResourceTypes{
+		gvk: []v1.GroupVersionKind{
+			{Group: "batch", Version: "v1", Kind: "CronJob"},
+		},
 	}
+}
 
+// NewResourceTypesFilterForRequestedBackupAction decides that RequestedBackupAction is owning only runnable objects (JOBS, Pods, Argo Workflows, Tekton runs).
+// Runnable kinds are coming from integrations enabled in the controller, see integration.AllSupportedJobResourceTypes.GetRunnableKinds()
+func NewResourceTypesFilterForRequestedBackupAction(runnable []schema.GroupVersionKind) ResourceTypes {
+	var gvk []v1.GroupVersionKind
+	for _, kind := range runnable {
+		gvk = append(gvk, v1.GroupVersionKind{Group: kind.Group, Version: kind.Version, Kind: kind.Kind})
+	}
 	return ResourceTypes{
 		gvk: gvk,
 	}
 }
 
-// NewResourceTypesFilterForRequestedBackupAction decides that RequestedBackupAction is owning only runnable objects (JOBS)
-func NewResourceTypesFilterForRequestedBackupAction() ResourceTypes {
+// NewResourceTypesFilterForRestoreTarget decides that RequestedBackupAction restoring into a different target is owning also its own helper objects,
+// as those rendered by ScheduledBackup are in a different namespace or contain different vars
+func NewResourceTypesFilterForRestoreTarget(runnable []schema.GroupVersionKind) ResourceTypes {
+	helpers := NewResourceTypesFilterForScheduledBackup()
+	runnableTypes := NewResourceTypesFilterForRequestedBackupAction(runnable)
 	return ResourceTypes{
-		gvk: []v1.GroupVersionKind{
-			{Group: "batch", Version: "v1", Kind: "Job"},
-		},
+		gvk: append(helpers.GetKinds(), runnableTypes.GetKinds()...),
+	}
+}
+
+// IsRunnableKindType tells if .spec.kindType of RequestedBackupAction is one of runnable kinds
+func IsRunnableKindType(runnable []schema.GroupVersionKind, kindType string) bool {
+	for _, kind := range runnable {
+		if kind.Kind == kindType {
+			return true
+		}
+	}
+	return false
+}
+
+// RunnableKindTypeNames are names of runnable kinds, as used in .spec.kindType
+func RunnableKindTypeNames(runnable []schema.GroupVersionKind) []string {
+	names := make([]string, 0, len(runnable))
+	for _, kind := range runnable {
+		names = append(names, kind.Kind)
 	}
+	return names
 }
@@ -18,6 +18,9 @@ type ScheduledBackupAggregate struct {
 	TokenSecret        *v1.Secret
 	VarsListSecret     *v1.Secret
 	AdditionalVarsList AdditionalVarsList
+
+	// VarsOverrides are merged on top of .spec.vars, e.g. vars of .spec.restoreTarget of RequestedBackupAction
+	VarsOverrides v1alpha1.VarsSpec
 }
 
 func (sb ScheduledBackupAggregate) AcceptedResourceTypes() []metav1.GroupVersionKind {
@@ -28,8 +31,19 @@ func (sb ScheduledBackupAggregate) GetOperation() Operation {
 	return Operation(sb.Spec.Operation)
 }
 
-func (sb ScheduledBackupAggregate) ShouldCreateCronJob() bool {
-	return sb.Spec.CronJob.Enabled
+// GetSchedules is returning schedules, for which CronJobs should be created
+func (sb ScheduledBackupAggregate) GetSchedules() []v1alpha1.ScheduleSpec {
+	return sb.Spec.GetSchedules()
+}
+
+// GetSchedule is nil, as the whole ScheduledBackup is not limited to a single schedule, see ScheduleRenderable
+func (sb ScheduledBackupAggregate) GetSchedule() *v1alpha1.ScheduleSpec {
+	return nil
+}
+
+// GetTimeout is returning a maximum duration of a single run, nil means no limit set by the user
+func (sb ScheduledBackupAggregate) GetTimeout() *metav1.Duration {
+	return sb.Spec.Timeout
 }
 
 func (sb ScheduledBackupAggregate) GetTemplate() Template {
@@ -52,11 +66,56 @@ func (sb ScheduledBackupAggregate) GetReferencesOfOwnedObjects() v1alpha1.Childr
 	return sb.ScheduledBackup.Status.OwnedReferences
 }
 
+// ForgetOwnedObjects is clearing the list of children, so it could be filled up again only with freshly applied objects.
+// Returns the previous list
+func (sb *ScheduledBackupAggregate) ForgetOwnedObjects() v1alpha1.ChildrenReferences {
+	previous := sb.Status.OwnedReferences
+	sb.Status.OwnedReferences = v1alpha1.ChildrenReferences{}
+	return previous
+}
+
 // AddOwnedObject is adding a child element
 func (sb *ScheduledBackupAggregate) AddOwnedObject(doc *unstructured.Unstructured) {
-	v1alpha1.AddOwnedObject(&sb.Status.OwnedReferences, doc)
+	v1alpha1.AddOwnedObject(&sb.Status.OwnedReferences, doc, sb.Namespace)
 }
 
 func (sb *ScheduledBackupAggregate) ShouldRenderDependentObjectsForAllOperationTypes() bool {
 	return true
 }
+
+func (sb *ScheduledBackupAggregate) ShouldRenderDependentObjectsForOwnOperation() bool {
+	return false
+}
+
+// GetTargetNamespace is returning a namespace, where the rendered objects are applied
+func (sb ScheduledBackupAggregate) GetTargetNamespace() string {
+	return sb.Namespace
+}
+
+func (sb ScheduledBackupAggregate) GetExtraEnv() map[string]string {
+	return nil
+}
+
+// GetResourceName is a base name of rendered objects, e.g. "app1" for "app1-backup" ConfigMap
+func (sb ScheduledBackupAggregate) GetResourceName() string {
+	return sb.Name
+}
+
+// ScheduleRenderable is narrowing a ScheduledBackup down to a single schedule, so each schedule renders its own CronJob
+type ScheduleRenderable struct {
+	Renderable
+	Schedule v1alpha1.ScheduleSpec
+}
+
+func NewScheduleRenderable(backup Renderable, schedule v1alpha1.ScheduleSpec) ScheduleRenderable {
+	return ScheduleRenderable{Renderable: backup, Schedule: schedule}
+}
+
+func (s ScheduleRenderable) GetOperation() Operation {
+	return Operation(s.Schedule.Operation)
+}
+
+// GetSchedule is returning the schedule, for which the CronJob is rendered
+func (s ScheduleRenderable) GetSchedule() *v1alpha1.ScheduleSpec {
+	return &s.Schedule
+}
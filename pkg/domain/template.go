@@ -1,5 +1,14 @@
 package domain
 
+const (
+	// ClusterBackupProcedureTemplateKind is a cluster-scoped template, shared between all namespaces
+	ClusterBackupProcedureTemplateKind = "ClusterBackupProcedureTemplate"
+
+	// BackupProcedureTemplateKind is a namespaced template, visible only in its own namespace.
+	// When not found in the namespace, then a ClusterBackupProcedureTemplate of the same name is looked up
+	BackupProcedureTemplateKind = "BackupProcedureTemplate"
+)
+
 type Template interface {
 	GetImage() string
 	GetBackupScript() string
@@ -7,3 +16,8 @@ type Template interface {
 	ProvidesScript() bool
 	GetName() string
 }
+
+// IsKnownTemplateKind tells if the .spec.templateRef.kind points to a supported kind of template
+func IsKnownTemplateKind(kind string) bool {
+	return kind == InternalTemplateKind || kind == ClusterBackupProcedureTemplateKind || kind == BackupProcedureTemplateKind
+}
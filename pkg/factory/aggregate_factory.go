@@ -27,6 +27,9 @@ func NewFactory(client client.Client, fetcher CachedFetcher, logger *logrus.Entr
 
 // CreateScheduledBackupAggregate is creating a fully hydrated object (aggregate) with all dependencies inside
 func (c *Factory) CreateScheduledBackupAggregate(ctx context.Context, backup *v1alpha1.ScheduledBackup, operation string) (*domain.ScheduledBackupAggregate, error, error) {
+	// objects created before the defaulting webhook was enabled could miss the defaults
+	backup.Spec.Default()
+
 	aggregate := domain.ScheduledBackupAggregate{ScheduledBackup: backup}
 	aggregate.AdditionalVarsList = make(map[string][]byte)
 
@@ -70,6 +73,7 @@ func (c *Factory) CreateScheduledBackupAggregate(ctx context.Context, backup *v1
 
 // CreateRequestedBackupActionAggregate is creating a fully hydrated object (aggregate) with all dependencies inside
 func (c *Factory) CreateRequestedBackupActionAggregate(ctx context.Context, action *v1alpha1.RequestedBackupAction, scheduledBackup *v1alpha1.ScheduledBackup) (*domain.RequestedBackupActionAggregate, error, error) {
+	action.Spec.Default()
 	scheduledBackupAggregate, _, fetchErr := c.CreateScheduledBackupAggregate(ctx, scheduledBackup, action.Spec.Action)
 	a := domain.NewRequestedBackupActionAggregate(action, scheduledBackupAggregate)
 	if fetchErr != nil {
@@ -78,7 +82,11 @@ func (c *Factory) CreateRequestedBackupActionAggregate(ctx context.Context, acti
 
 	// modify it according to the current ACTION
 	scheduledBackup.Spec.CronJob.Enabled = false        // we cannot generate a CronJob in this case :-)
+	scheduledBackup.Spec.Schedules = nil                // neither any other CronJob
 	scheduledBackup.Spec.Operation = action.Spec.Action // we should enforce an action as RequestedBackupAction is a manual TRIGGER for ScheduledBackup
+	if a.HasRestoreTarget() {
+		scheduledBackupAggregate.VarsOverrides = action.Spec.RestoreTarget.Vars
+	}
 
 	return a, nil, nil
 }
@@ -156,15 +164,19 @@ func (c *Factory) hydrateTemplate(ctx context.Context, a *domain.ScheduledBackup
 		return nil
 	}
 
+	if !domain.IsKnownTemplateKind(a.ScheduledBackup.Spec.TemplateRef.Kind) {
+		return errors.Errorf("unsupported .spec.templateRef.kind '%s'", a.ScheduledBackup.Spec.TemplateRef.Kind)
+	}
+
 	//
-	// Cluster Templates are defined as CRD in Kubernetes
+	// Templates are defined as CRD in Kubernetes - namespaced BackupProcedureTemplate or cluster-wide ClusterBackupProcedureTemplate
 	//
 	tpl, tplErr := c.fetcher.fetchTemplate(ctx, a.ScheduledBackup)
 	if tplErr != nil {
-		return errors.Wrap(tplErr, "cannot fetch ClusterBackupProcedureTemplate type object")
+		return errors.Wrapf(tplErr, "cannot fetch %s type object", a.ScheduledBackup.Spec.TemplateRef.Kind)
 	}
 	a.Template = tpl
-	c.logger.Info(fmt.Sprintf("Fetched '%s' template", tpl.Name))
+	c.logger.Info(fmt.Sprintf("Fetched '%s' template", tpl.GetName()))
 	return nil
 }
 
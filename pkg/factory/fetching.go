@@ -8,17 +8,18 @@ import (
 	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"time"
 )
 
-// CachedFetcher is fetching objects collected by the controller-runtime
+// CachedFetcher is fetching objects collected by the controller-runtime.
+// Cache is usually the manager's cache.Cache, but any reader works e.g. a fake client when rendering offline
 type CachedFetcher struct {
-	Cache  cache.Cache
+	Cache  client.Reader
 	Client v1alpha1.RiotkitV1alpha1Interface
 }
 
@@ -35,10 +36,26 @@ func (r *CachedFetcher) FetchRequestedBackupAction(ctx context.Context, req ctrl
 	return backup, getErr
 }
 
-// fetchTemplate is fetching a template from cache
-func (r *CachedFetcher) fetchTemplate(ctx context.Context, backup *riotkitorgv1alpha1.ScheduledBackup) (*riotkitorgv1alpha1.ClusterBackupProcedureTemplate, error) {
+// fetchTemplate is fetching a template from cache.
+// `kind: BackupProcedureTemplate` is looked up in the ScheduledBackup's namespace first, then falls back to `kind: ClusterBackupProcedureTemplate`
+func (r *CachedFetcher) fetchTemplate(ctx context.Context, backup *riotkitorgv1alpha1.ScheduledBackup) (domain.Template, error) {
+	if backup.Spec.TemplateRef.Kind == domain.BackupProcedureTemplateKind {
+		template := riotkitorgv1alpha1.BackupProcedureTemplate{}
+		getErr := r.Cache.Get(ctx, client.ObjectKey{Name: backup.Spec.TemplateRef.Name, Namespace: backup.Namespace}, &template)
+		if getErr == nil {
+			return &template, nil
+		}
+		if !apierrors.IsNotFound(getErr) {
+			return nil, getErr
+		}
+	}
+	return r.fetchClusterTemplate(ctx, backup.Spec.TemplateRef.Name)
+}
+
+// fetchClusterTemplate is fetching a cluster-scoped template from cache
+func (r *CachedFetcher) fetchClusterTemplate(ctx context.Context, name string) (*riotkitorgv1alpha1.ClusterBackupProcedureTemplate, error) {
 	template := riotkitorgv1alpha1.ClusterBackupProcedureTemplate{}
-	getErr := r.Cache.Get(ctx, client.ObjectKey{Name: backup.Spec.TemplateRef.Name}, &template)
+	getErr := r.Cache.Get(ctx, client.ObjectKey{Name: name}, &template)
 	return &template, getErr
 }
 
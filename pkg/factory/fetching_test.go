@@ -0,0 +1,79 @@
+package factory
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+func createFetcher(t *testing.T, objects ...client.Object) CachedFetcher {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	return CachedFetcher{Cache: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+}
+
+func createBackupUsingTemplate(kind string) *v1alpha1.ScheduledBackup {
+	return &v1alpha1.ScheduledBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "team-a"},
+		Spec:       v1alpha1.ScheduledBackupSpec{TemplateRef: v1alpha1.TemplateSpec{Kind: kind, Name: "pg15"}},
+	}
+}
+
+var (
+	namespacedTemplate     = &v1alpha1.BackupProcedureTemplate{ObjectMeta: metav1.ObjectMeta{Name: "pg15", Namespace: "team-a"}}
+	otherNamespaceTemplate = &v1alpha1.BackupProcedureTemplate{ObjectMeta: metav1.ObjectMeta{Name: "pg15", Namespace: "team-b"}}
+	clusterTemplate        = &v1alpha1.ClusterBackupProcedureTemplate{ObjectMeta: metav1.ObjectMeta{Name: "pg15"}}
+)
+
+func TestCachedFetcher_FetchTemplate_OnlyNamespaced(t *testing.T) {
+	fetcher := createFetcher(t, namespacedTemplate)
+
+	tpl, err := fetcher.fetchTemplate(context.TODO(), createBackupUsingTemplate(domain.BackupProcedureTemplateKind))
+
+	assert.Nil(t, err)
+	assert.IsType(t, &v1alpha1.BackupProcedureTemplate{}, tpl)
+	assert.Equal(t, "team-a", tpl.(*v1alpha1.BackupProcedureTemplate).Namespace)
+
+	// a cluster-wide template is never looked up in namespaces
+	_, err = fetcher.fetchTemplate(context.TODO(), createBackupUsingTemplate(domain.ClusterBackupProcedureTemplateKind))
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestCachedFetcher_FetchTemplate_OnlyCluster(t *testing.T) {
+	fetcher := createFetcher(t, clusterTemplate)
+
+	for _, kind := range []string{domain.BackupProcedureTemplateKind, domain.ClusterBackupProcedureTemplateKind} {
+		tpl, err := fetcher.fetchTemplate(context.TODO(), createBackupUsingTemplate(kind))
+
+		assert.Nil(t, err)
+		assert.IsType(t, &v1alpha1.ClusterBackupProcedureTemplate{}, tpl, "Expected a fallback to the cluster-wide template for kind '%s'", kind)
+	}
+}
+
+func TestCachedFetcher_FetchTemplate_NamespacedWins(t *testing.T) {
+	fetcher := createFetcher(t, clusterTemplate, namespacedTemplate)
+
+	tpl, err := fetcher.fetchTemplate(context.TODO(), createBackupUsingTemplate(domain.BackupProcedureTemplateKind))
+
+	assert.Nil(t, err)
+	assert.IsType(t, &v1alpha1.BackupProcedureTemplate{}, tpl)
+}
+
+// TestCachedFetcher_FetchTemplate_OtherNamespace is checking that a template of other team is not used, even if it has the same name
+func TestCachedFetcher_FetchTemplate_OtherNamespace(t *testing.T) {
+	fetcher := createFetcher(t, otherNamespaceTemplate)
+	_, err := fetcher.fetchTemplate(context.TODO(), createBackupUsingTemplate(domain.BackupProcedureTemplateKind))
+	assert.True(t, apierrors.IsNotFound(err))
+
+	fetcher = createFetcher(t, otherNamespaceTemplate, clusterTemplate)
+	tpl, err := fetcher.fetchTemplate(context.TODO(), createBackupUsingTemplate(domain.BackupProcedureTemplateKind))
+	assert.Nil(t, err)
+	assert.IsType(t, &v1alpha1.ClusterBackupProcedureTemplate{}, tpl)
+}
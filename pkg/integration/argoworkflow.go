@@ -0,0 +1,106 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Phases of Argo Workflow, as in .status.phase
+const (
+	workflowPhaseSucceeded = "Succeeded"
+	workflowPhaseFailed    = "Failed"
+	workflowPhaseError     = "Error"
+)
+
+// workflowDeadlineExceededMessage is set by Argo as .status.message, when the Workflow was running longer than .spec.activeDeadlineSeconds
+const workflowDeadlineExceededMessage = "Max duration limit exceeded"
+
+var (
+	argoWorkflowGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Workflow"}
+	argoWorkflowGVR = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "workflows"}
+)
+
+func init() {
+	Register(Adapter{
+		Name:     "argo-workflow",
+		Kind:     argoWorkflowGVK,
+		Runnable: true,
+		Owns:     []client.Object{newOwnedUnstructured(argoWorkflowGVK)},
+		New: func(cfg *rest.Config) (SupportedJobResourceType, error) {
+			return NewArgoWorkflowResourceType(cfg)
+		},
+	})
+}
+
+// ArgoWorkflowResourceType is following Argo Workflows. There is no dependency on Argo's Go client, the Workflow is read through the dynamic client
+type ArgoWorkflowResourceType struct {
+	client dynamic.Interface
+}
+
+func (aw ArgoWorkflowResourceType) GetScheduledJobHealthStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.JobHealthStatus, error) {
+	list, err := aw.client.Resource(argoWorkflowGVR).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: v1alpha1.LabelTrackingId + "=" + trackingId})
+	if err != nil {
+		return v1alpha1.JobHealthStatus{}, errors.Wrap(err, "cannot list Argo Workflows")
+	}
+	if len(list.Items) == 0 {
+		return v1alpha1.JobHealthStatus{}, errors.Errorf("cannot find any Workflow labelled with %s=%s", v1alpha1.LabelTrackingId, trackingId)
+	}
+
+	running := false
+	var name string
+	for _, workflow := range list.Items {
+		phase, _, _ := unstructured.NestedString(workflow.Object, "status", "phase")
+		message, _, _ := unstructured.NestedString(workflow.Object, "status", "message")
+
+		// "Error" is a failure of Argo itself, e.g. the Pod could not be created
+		if phase == workflowPhaseFailed || phase == workflowPhaseError {
+			return v1alpha1.JobHealthStatus{
+				ChildReference: v1alpha1.ChildReference{
+					APIVersion: "argoproj.io/v1alpha1",
+					Kind:       "Workflow",
+					TrackingId: trackingId,
+					Name:       workflow.GetName(),
+				},
+				Message:  fmt.Sprintf("Workflow %s/%s %s: %s", workflow.GetNamespace(), workflow.GetName(), phase, message),
+				Failed:   true,
+				TimedOut: phase == workflowPhaseFailed && message == workflowDeadlineExceededMessage,
+			}, nil
+		}
+		// not started yet ("" or "Pending") or "Running"
+		if phase != workflowPhaseSucceeded {
+			running = true
+		}
+		name = workflow.GetName()
+	}
+
+	return v1alpha1.JobHealthStatus{
+		ChildReference: v1alpha1.ChildReference{
+			APIVersion: "argoproj.io/v1alpha1",
+			Kind:       "Workflow",
+			TrackingId: trackingId,
+			Name:       name,
+		},
+		Message:   fmt.Sprintf("All Workflows labelled with %s=%s succeed in %s namespace", v1alpha1.LabelTrackingId, trackingId, namespace),
+		Succeeded: !running,
+		Running:   running,
+	}, nil
+}
+
+// NewArgoWorkflowResourceType is creating an instance of a `Kind: Workflow` checker
+func NewArgoWorkflowResourceType(cfg *rest.Config) (ArgoWorkflowResourceType, error) {
+	dynamicClient, clErr := dynamic.NewForConfig(cfg)
+	if clErr != nil {
+		return ArgoWorkflowResourceType{}, errors.Wrap(clErr, "cannot create dynamic client")
+	}
+	return ArgoWorkflowResourceType{
+		dynamicClient,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"testing"
+)
+
+func createWorkflow(name string, phase string, message string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Workflow",
+		"metadata": map[string]interface{}{
+			"name": name, "namespace": "team-a",
+			"labels": map[string]interface{}{v1alpha1.LabelTrackingId: "aaa"},
+		},
+		"status": map[string]interface{}{"phase": phase, "message": message},
+	}}
+}
+
+func createArgoWorkflowAdapter(objects ...runtime.Object) ArgoWorkflowResourceType {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{argoWorkflowGVR: "WorkflowList"}, objects...)
+	return ArgoWorkflowResourceType{client: client}
+}
+
+// TestArgoWorkflowResourceType_Phases is checking that Workflow phases are translated into the health status
+func TestArgoWorkflowResourceType_Phases(t *testing.T) {
+	for _, tc := range []struct {
+		phase                               string
+		message                             string
+		running, succeeded, failed, timeout bool
+	}{
+		{phase: "", running: true},
+		{phase: "Pending", running: true},
+		{phase: "Running", running: true},
+		{phase: "Succeeded", succeeded: true},
+		{phase: "Failed", message: "child 'app1-restore-x7k2p' failed", failed: true},
+		{phase: "Failed", message: "Max duration limit exceeded", failed: true, timeout: true},
+		{phase: "Error", message: "pods \"app1-restore-x7k2p\" is forbidden", failed: true},
+	} {
+		health, err := createArgoWorkflowAdapter(createWorkflow("app1-restore-x7k2p", tc.phase, tc.message)).
+			GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.running, health.Running, "phase: %s", tc.phase)
+		assert.Equal(t, tc.succeeded, health.Succeeded, "phase: %s", tc.phase)
+		assert.Equal(t, tc.failed, health.Failed, "phase: %s", tc.phase)
+		assert.Equal(t, tc.timeout, health.TimedOut, "phase: %s", tc.phase)
+		assert.Equal(t, "app1-restore-x7k2p", health.Name)
+		assert.Equal(t, "Workflow", health.Kind)
+	}
+}
+
+// TestArgoWorkflowResourceType_NotFound is checking that a missing Workflow is reported as an error, not as a success
+func TestArgoWorkflowResourceType_NotFound(t *testing.T) {
+	_, err := createArgoWorkflowAdapter().GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot find any Workflow")
+}
@@ -0,0 +1,159 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	v1 "k8s.io/client-go/kubernetes/typed/batch/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KubernetesCronJobResourceType is following Jobs spawned by a CronJob. The health of a CronJob is the result of its latest finished run
+type KubernetesCronJobResourceType struct {
+	client v1.BatchV1Interface
+	// core is used to describe failed Pods of the latest run
+	core corev1client.CoreV1Interface
+}
+
+var cronJobGVK = schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"}
+
+func init() {
+	Register(Adapter{
+		Name: "cronjob",
+		Kind: cronJobGVK,
+		// Jobs spawned by the CronJob are not owned by ScheduledBackup directly
+		Owns: []client.Object{&batchv1.CronJob{}},
+		New: func(cfg *rest.Config) (SupportedJobResourceType, error) {
+			return NewKubernetesCronJobResourceType(cfg)
+		},
+	})
+}
+
+func (kc KubernetesCronJobResourceType) GetScheduledJobHealthStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.JobHealthStatus, error) {
+	cronJob, status, jobs, err := kc.observe(ctx, trackingId, namespace)
+	if err != nil {
+		return v1alpha1.JobHealthStatus{}, err
+	}
+
+	health := v1alpha1.JobHealthStatus{
+		ChildReference: v1alpha1.ChildReference{
+			APIVersion: "batch/v1",
+			Kind:       "CronJob",
+			TrackingId: trackingId,
+			Name:       cronJob.Name,
+		},
+		Running: len(cronJob.Status.Active) > 0,
+		Message: fmt.Sprintf("CronJob %s/%s did not finish any run yet", namespace, cronJob.Name),
+	}
+	if latest := status.GetLatestFinishedRun(); latest != nil {
+		health.Succeeded = latest.Outcome == v1alpha1.RunSucceeded
+		health.Failed = !health.Succeeded
+		health.TimedOut = latest.Outcome == v1alpha1.RunTimedOut
+		health.Message = fmt.Sprintf("Latest finished run of CronJob %s/%s: %s - %s", namespace, cronJob.Name, latest.JobName, latest.Outcome)
+
+		// the Job could be already deleted by Kubernetes, according to .spec.failedJobsHistoryLimit
+		if job, exists := jobs[latest.JobName]; exists && health.Failed {
+			health.Failure = describeJobFailure(ctx, kc.core, job)
+			if health.Failure != nil {
+				health.Message += ", " + describeFailure(health.Failure)
+			}
+		}
+	}
+	return health, nil
+}
+
+// GetCronJobStatus is returning runs of the CronJob, that are still kept by Kubernetes
+func (kc KubernetesCronJobResourceType) GetCronJobStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.CronJobStatus, error) {
+	_, status, _, err := kc.observe(ctx, trackingId, namespace)
+	return status, err
+}
+
+// observe is returning the CronJob, its runs and Jobs spawned by it (by name)
+func (kc KubernetesCronJobResourceType) observe(ctx context.Context, trackingId string, namespace string) (batchv1.CronJob, v1alpha1.CronJobStatus, map[string]batchv1.Job, error) {
+	cronJobs, err := kc.client.CronJobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: v1alpha1.LabelTrackingId + "=" + trackingId})
+	if err != nil {
+		return batchv1.CronJob{}, v1alpha1.CronJobStatus{}, nil, errors.Wrap(err, "cannot list CronJobs")
+	}
+	if len(cronJobs.Items) == 0 {
+		return batchv1.CronJob{}, v1alpha1.CronJobStatus{}, nil, errors.Errorf("cannot find any CronJob labelled with %s=%s", v1alpha1.LabelTrackingId, trackingId)
+	}
+	cronJob := cronJobs.Items[0]
+
+	// Jobs are labelled with the tracking id through the CronJob's .spec.jobTemplate. Jobs spawned before the CronJob was applied
+	// last time are carrying a previous id - their runs are already recorded in the ScheduledBackup's status
+	jobs, err := kc.client.Jobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: v1alpha1.LabelTrackingId + "=" + trackingId})
+	if err != nil {
+		return batchv1.CronJob{}, v1alpha1.CronJobStatus{}, nil, errors.Wrap(err, "cannot list Jobs spawned by CronJob")
+	}
+
+	status := v1alpha1.CronJobStatus{
+		LastScheduleTime:   cronJob.Status.LastScheduleTime,
+		LastSuccessfulTime: cronJob.Status.LastSuccessfulTime,
+	}
+	spawned := make(map[string]batchv1.Job)
+	for _, job := range jobs.Items {
+		if !metav1.IsControlledBy(&job, &cronJob) {
+			continue
+		}
+		spawned[job.Name] = job
+		startTime := job.Status.StartTime
+		if startTime == nil {
+			startTime = &job.CreationTimestamp
+		}
+		run := v1alpha1.CronJobRun{JobName: job.Name, Outcome: getRunOutcome(job), StartTime: startTime}
+		if run.Outcome.IsFinished() {
+			run.CompletionTime = getJobFinishTime(job)
+		}
+		status.LastRuns = append(status.LastRuns, run)
+	}
+	return cronJob, status, spawned, nil
+}
+
+// getRunOutcome is translating Job conditions into an outcome of a single run
+func getRunOutcome(job batchv1.Job) v1alpha1.RunOutcome {
+	if isJobDeadlineExceeded(job) {
+		return v1alpha1.RunTimedOut
+	}
+	if hasJobCondition(job, batchv1.JobFailed) {
+		return v1alpha1.RunFailed
+	}
+	if hasJobCondition(job, batchv1.JobComplete) {
+		return v1alpha1.RunSucceeded
+	}
+	return v1alpha1.RunRunning
+}
+
+// getJobFinishTime is returning a time when the Job has succeeded or failed
+func getJobFinishTime(job batchv1.Job) *metav1.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed || condition.Type == batchv1.JobComplete {
+			return condition.LastTransitionTime.DeepCopy()
+		}
+	}
+	return nil
+}
+
+// NewKubernetesCronJobResourceType is creating an instance of a `Kind: CronJob` checker
+func NewKubernetesCronJobResourceType(cfg *rest.Config) (KubernetesCronJobResourceType, error) {
+	batchClient, clErr := v1.NewForConfig(cfg)
+	if clErr != nil {
+		return KubernetesCronJobResourceType{}, errors.Wrap(clErr, "cannot create batch/v1 client")
+	}
+	coreClient, clErr := corev1client.NewForConfig(cfg)
+	if clErr != nil {
+		return KubernetesCronJobResourceType{}, errors.Wrap(clErr, "cannot create core/v1 client")
+	}
+	return KubernetesCronJobResourceType{
+		client: batchClient,
+		core:   coreClient,
+	}, nil
+}
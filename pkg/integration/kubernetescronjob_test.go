@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+var testCronJob = &batchv1.CronJob{
+	TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "app1-backup", Namespace: "team-a", UID: "cronjob-uid",
+		Labels: map[string]string{v1alpha1.LabelTrackingId: "aaa"},
+	},
+}
+
+func createJobSpawnedBy(cronJob *batchv1.CronJob, name string, startedAt time.Time, conditions ...batchv1.JobCondition) *batchv1.Job {
+	controller := true
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: cronJob.Namespace,
+			Labels:          map[string]string{v1alpha1.LabelTrackingId: cronJob.Labels[v1alpha1.LabelTrackingId]},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: cronJob.Name, UID: cronJob.UID, Controller: &controller}},
+		},
+		Status: batchv1.JobStatus{StartTime: &metav1.Time{Time: startedAt}, Conditions: conditions},
+	}
+}
+
+func createCronJobAdapter(objects ...runtime.Object) KubernetesCronJobResourceType {
+	clientset := fake.NewSimpleClientset(objects...)
+	return KubernetesCronJobResourceType{client: clientset.BatchV1(), core: clientset.CoreV1()}
+}
+
+// TestKubernetesCronJobResourceType_HealthFromLatestFinishedRun is checking that the health is taken from the newest finished Job, not from the running one
+func TestKubernetesCronJobResourceType_HealthFromLatestFinishedRun(t *testing.T) {
+	now := time.Date(2023, 1, 3, 2, 0, 0, 0, time.UTC)
+	cronJob := testCronJob.DeepCopy()
+	cronJob.Status.Active = []corev1.ObjectReference{{Name: "app1-backup-3"}}
+
+	adapter := createCronJobAdapter(
+		cronJob,
+		createJobSpawnedBy(cronJob, "app1-backup-1", now.Add(-time.Hour*48), batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}),
+		createJobSpawnedBy(cronJob, "app1-backup-2", now.Add(-time.Hour*24), batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"}),
+		createJobSpawnedBy(cronJob, "app1-backup-3", now),
+		createJobSpawnedBy(&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-a", UID: "other-uid"}}, "other-1", now),
+		// a label copied by the user into an unrelated Job
+		createJobSpawnedBy(&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "copy", Namespace: "team-a", UID: "copy-uid", Labels: testCronJob.Labels}}, "copy-1", now),
+	)
+
+	health, err := adapter.GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+	assert.Nil(t, err)
+	assert.True(t, health.Running)
+	assert.True(t, health.Failed)
+	assert.True(t, health.TimedOut)
+	assert.Equal(t, "app1-backup", health.Name)
+
+	status, err := adapter.GetCronJobStatus(context.TODO(), "aaa", "team-a")
+	assert.Nil(t, err)
+	assert.Len(t, status.LastRuns, 3, "Expected that Jobs of other CronJobs are not counted")
+}
+
+// TestKubernetesCronJobResourceType_NoRunsYet is checking that a fresh CronJob is not reported as failed
+func TestKubernetesCronJobResourceType_NoRunsYet(t *testing.T) {
+	health, err := createCronJobAdapter(testCronJob.DeepCopy()).GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+
+	assert.Nil(t, err)
+	assert.False(t, health.Failed)
+	assert.False(t, health.Running)
+	assert.Contains(t, health.Message, "did not finish any run yet")
+
+	_, err = createCronJobAdapter().GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+	assert.NotNil(t, err)
+}
+
+// TestCronJobStatus_Record is checking that each finished run is counted once, even if Kubernetes already deleted the Job
+func TestCronJobStatus_Record(t *testing.T) {
+	day := func(d int) *metav1.Time { return &metav1.Time{Time: time.Date(2023, 1, d, 2, 0, 0, 0, time.UTC)} }
+	status := v1alpha1.CronJobStatus{}
+
+	status.Record(v1alpha1.CronJobStatus{LastScheduleTime: day(1), LastRuns: []v1alpha1.CronJobRun{
+		{JobName: "app1-backup-1", Outcome: v1alpha1.RunFailed, StartTime: day(1)},
+	}})
+	finished := status.Record(v1alpha1.CronJobStatus{LastScheduleTime: day(2), LastRuns: []v1alpha1.CronJobRun{
+		{JobName: "app1-backup-1", Outcome: v1alpha1.RunFailed, StartTime: day(1)},
+		{JobName: "app1-backup-2", Outcome: v1alpha1.RunRunning, StartTime: day(2)},
+	}})
+	assert.Equal(t, 1, status.ConsecutiveFailures)
+	assert.Empty(t, finished, "Expected that already recorded and still running runs are not reported as finished")
+
+	// "app1-backup-1" was deleted by Kubernetes, "app1-backup-2" has finished
+	finished = status.Record(v1alpha1.CronJobStatus{LastRuns: []v1alpha1.CronJobRun{
+		{JobName: "app1-backup-2", Outcome: v1alpha1.RunTimedOut, StartTime: day(2)},
+	}})
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	assert.Len(t, finished, 1)
+	assert.Equal(t, v1alpha1.RunTimedOut, finished[0].Outcome)
+	assert.Equal(t, day(2), status.LastScheduleTime)
+	assert.Equal(t, []string{"app1-backup-2", "app1-backup-1"}, []string{status.LastRuns[0].JobName, status.LastRuns[1].JobName})
+
+	// success resets the counter
+	status.Record(v1alpha1.CronJobStatus{LastSuccessfulTime: day(3), LastRuns: []v1alpha1.CronJobRun{
+		{JobName: "app1-backup-3", Outcome: v1alpha1.RunSucceeded, StartTime: day(3)},
+	}})
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.Equal(t, "app1-backup-3", status.GetLatestFinishedRun().JobName)
+
+	// only last runs are kept
+	for d := 4; d < 4+v1alpha1.CronJobRunsHistoryLimit; d++ {
+		status.Record(v1alpha1.CronJobStatus{LastRuns: []v1alpha1.CronJobRun{{JobName: day(d).String(), Outcome: v1alpha1.RunSucceeded, StartTime: day(d)}}})
+	}
+	assert.Len(t, status.LastRuns, v1alpha1.CronJobRunsHistoryLimit)
+	assert.Equal(t, day(4+v1alpha1.CronJobRunsHistoryLimit-1), status.LastRuns[0].StartTime)
+}
+
+// TestScheduledBackupStatus_RecordCronJobRuns is checking that each schedule has a separate history, and the history of a removed schedule is forgotten
+func TestScheduledBackupStatus_RecordCronJobRuns(t *testing.T) {
+	daily := v1alpha1.ScheduleSpec{Operation: "backup", ScheduleEvery: "00 02 * * *"}
+	verify := v1alpha1.ScheduleSpec{Name: "verify", Operation: "restore", ScheduleEvery: "00 04 * * 0"}
+	failed := v1alpha1.CronJobStatus{LastRuns: []v1alpha1.CronJobRun{{JobName: "app1-restore-verify-1", Outcome: v1alpha1.RunFailed}}}
+	status := v1alpha1.ScheduledBackupStatus{}
+
+	status.RecordCronJobRuns(daily, v1alpha1.CronJobStatus{LastRuns: []v1alpha1.CronJobRun{{JobName: "app1-backup-1", Outcome: v1alpha1.RunSucceeded}}})
+	status.RecordCronJobRuns(verify, failed)
+	assert.Equal(t, 0, status.CronJob.ConsecutiveFailures)
+	assert.Equal(t, 1, status.GetCronJobStatus(verify).ConsecutiveFailures)
+	assert.Equal(t, "restore", status.Schedules[0].Operation)
+
+	// a schedule changed its operation, the previous runs are not its history anymore
+	verify.Operation = "backup"
+	status.RecordCronJobRuns(verify, v1alpha1.CronJobStatus{})
+	assert.Equal(t, 0, status.GetCronJobStatus(verify).ConsecutiveFailures)
+
+	status.ForgetRemovedSchedules([]v1alpha1.ScheduleSpec{daily})
+	assert.Nil(t, status.GetCronJobStatus(verify))
+	assert.NotNil(t, status.GetCronJobStatus(daily))
+}
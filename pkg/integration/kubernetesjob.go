@@ -5,23 +5,32 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	v1 "k8s.io/client-go/kubernetes/typed/batch/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type KubernetesJobResourceType struct {
-	client *v1.BatchV1Client
+	client v1.BatchV1Interface
+	// core is used to describe failed Pods of the Job
+	core corev1client.CoreV1Interface
 }
 
-func (kj KubernetesJobResourceType) MatchesGVK(gvk schema.GroupVersionKind) bool {
-	comparison := schema.GroupVersionKind{
-		Group:   "batch",
-		Version: "v1",
-		Kind:    "Job",
-	}
-	return gvk.String() == comparison.String()
+func init() {
+	Register(Adapter{
+		Name:     "job",
+		Kind:     schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"},
+		Runnable: true,
+		Owns:     []client.Object{&batchv1.Job{}},
+		New: func(cfg *rest.Config) (SupportedJobResourceType, error) {
+			return NewKubernetesJobResourceType(cfg)
+		},
+	})
 }
 
 func (kj KubernetesJobResourceType) GetScheduledJobHealthStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.JobHealthStatus, error) {
@@ -32,6 +41,7 @@ func (kj KubernetesJobResourceType) GetScheduledJobHealthStatus(ctx context.Cont
 	}
 
 	var running = false
+	var name string
 	if len(list.Items) == 0 {
 		return v1alpha1.JobHealthStatus{}, errors.New(fmt.Sprintf("cannot find any job labelled with %s=%s", v1alpha1.LabelTrackingId, trackingId))
 	}
@@ -39,33 +49,43 @@ func (kj KubernetesJobResourceType) GetScheduledJobHealthStatus(ctx context.Cont
 	// iterate over all labelled jobs - we mostly expect a one object there
 	// but in case, when Backup Repository Client would produce more objects we are prepared for it
 	for _, job := range list.Items {
-		if job.Status.Failed > 0 {
+		if job.Status.Failed > 0 || hasJobCondition(job, batchv1.JobFailed) {
 			// if at least one job fails, then our workflow has failed and needs to be repeated
+			failure := describeJobFailure(ctx, kj.core, job)
+			message := fmt.Sprintf("Job %s/%s failed", job.GetNamespace(), job.GetName())
+			if failure != nil {
+				message += ": " + describeFailure(failure)
+			}
 			return v1alpha1.JobHealthStatus{
 				ChildReference: v1alpha1.ChildReference{
-					APIVersion: job.APIVersion,
-					Kind:       job.Kind,
+					APIVersion: "batch/v1",
+					Kind:       "Job",
 					TrackingId: job.Labels[v1alpha1.LabelTrackingId],
+					Name:       job.GetName(),
 				},
-				Message:   fmt.Sprintf("Job %s/%s failed", job.GetNamespace(), job.GetName()),
+				Message:   message,
 				Failed:    true,
+				TimedOut:  isJobDeadlineExceeded(job),
+				Failure:   failure,
 				Succeeded: false,
 				Running:   false,
 			}, nil
 		}
 
-		// count active or pending jobs
-		if job.Status.Active > 0 {
+		// a Job that is not completed yet is active or pending
+		if !hasJobCondition(job, batchv1.JobComplete) {
 			running = true
 		}
+		name = job.GetName()
 	}
 
 	// return one status for all matched jobs
 	return v1alpha1.JobHealthStatus{
 		ChildReference: v1alpha1.ChildReference{
-			APIVersion: "v1",
+			APIVersion: "batch/v1",
 			Kind:       "Job",
 			TrackingId: trackingId,
+			Name:       name,
 		},
 		Message:   fmt.Sprintf("All labelled jobs by %s=%s succeed in %s namespace", v1alpha1.LabelTrackingId, trackingId, namespace),
 		Failed:    false,
@@ -74,13 +94,38 @@ func (kj KubernetesJobResourceType) GetScheduledJobHealthStatus(ctx context.Cont
 	}, nil
 }
 
+// hasJobCondition tells if the Job has a condition of given type with status True
+func hasJobCondition(job batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// isJobDeadlineExceeded tells if the Job was killed, because it was running longer than .spec.activeDeadlineSeconds
+func isJobDeadlineExceeded(job batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue && condition.Reason == "DeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
 // NewKubernetesJobResourceType is creating an instance of a `Kind: Job` checker
-func NewKubernetesJobResourceType(cfg *rest.Config) KubernetesJobResourceType {
+func NewKubernetesJobResourceType(cfg *rest.Config) (KubernetesJobResourceType, error) {
 	batchClient, clErr := v1.NewForConfig(cfg)
 	if clErr != nil {
-		panic(clErr.Error())
+		return KubernetesJobResourceType{}, errors.Wrap(clErr, "cannot create batch/v1 client")
 	}
-	return KubernetesJobResourceType{
-		batchClient,
+	coreClient, clErr := corev1client.NewForConfig(cfg)
+	if clErr != nil {
+		return KubernetesJobResourceType{}, errors.Wrap(clErr, "cannot create core/v1 client")
 	}
+	return KubernetesJobResourceType{
+		client: batchClient,
+		core:   coreClient,
+	}, nil
 }
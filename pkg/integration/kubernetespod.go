@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podDeadlineExceededReason is set by Kubelet as .status.reason, when the Pod was running longer than .spec.activeDeadlineSeconds
+const podDeadlineExceededReason = "DeadlineExceeded"
+
+// KubernetesPodResourceType is following plain Pods. Pods are rendered with `restartPolicy: Never`, so a container that exited
+// with a non-zero code means a failure, even if the Pod is still running other containers
+type KubernetesPodResourceType struct {
+	client v1.CoreV1Interface
+}
+
+func init() {
+	Register(Adapter{
+		Name:     "pod",
+		Kind:     schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+		Runnable: true,
+		Owns:     []client.Object{&corev1.Pod{}},
+		New: func(cfg *rest.Config) (SupportedJobResourceType, error) {
+			return NewKubernetesPodResourceType(cfg)
+		},
+	})
+}
+
+func (kp KubernetesPodResourceType) GetScheduledJobHealthStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.JobHealthStatus, error) {
+	list, err := kp.client.Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: v1alpha1.LabelTrackingId + "=" + trackingId})
+	if err != nil {
+		return v1alpha1.JobHealthStatus{}, errors.Wrap(err, "cannot list Pods")
+	}
+	if len(list.Items) == 0 {
+		return v1alpha1.JobHealthStatus{}, errors.Errorf("cannot find any Pod labelled with %s=%s", v1alpha1.LabelTrackingId, trackingId)
+	}
+
+	running := false
+	var name string
+	for _, pod := range list.Items {
+		if failure := describePodFailure(ctx, kp.client, pod); failure != nil {
+			return v1alpha1.JobHealthStatus{
+				ChildReference: v1alpha1.ChildReference{
+					APIVersion: "v1",
+					Kind:       "Pod",
+					TrackingId: trackingId,
+					Name:       pod.GetName(),
+				},
+				Message:  fmt.Sprintf("Pod %s/%s failed: %s", pod.GetNamespace(), pod.GetName(), describeFailure(failure)),
+				Failed:   true,
+				TimedOut: pod.Status.Reason == podDeadlineExceededReason,
+				Failure:  failure,
+			}, nil
+		}
+		// Pending, Running, or Unknown, when the Node is not reachable
+		if pod.Status.Phase != corev1.PodSucceeded {
+			running = true
+		}
+		name = pod.GetName()
+	}
+
+	return v1alpha1.JobHealthStatus{
+		ChildReference: v1alpha1.ChildReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			TrackingId: trackingId,
+			Name:       name,
+		},
+		Message:   fmt.Sprintf("All Pods labelled with %s=%s succeed in %s namespace", v1alpha1.LabelTrackingId, trackingId, namespace),
+		Succeeded: !running,
+		Running:   running,
+	}, nil
+}
+
+// NewKubernetesPodResourceType is creating an instance of a `Kind: Pod` checker
+func NewKubernetesPodResourceType(cfg *rest.Config) (KubernetesPodResourceType, error) {
+	coreClient, clErr := v1.NewForConfig(cfg)
+	if clErr != nil {
+		return KubernetesPodResourceType{}, errors.Wrap(clErr, "cannot create core/v1 client")
+	}
+	return KubernetesPodResourceType{
+		coreClient,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+func createPod(status corev1.PodStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "app1-restore-x7k2p", Namespace: "team-a",
+			Labels: map[string]string{v1alpha1.LabelTrackingId: "aaa"},
+		},
+		Status: status,
+	}
+}
+
+func terminatedContainer(name string, exitCode int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Reason: "Error"}}}
+}
+
+// TestKubernetesPodResourceType_PhasesAndExitCodes is checking that Pod phase and container exit codes are translated into the health status
+func TestKubernetesPodResourceType_PhasesAndExitCodes(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status                              corev1.PodStatus
+		running, succeeded, failed, timeout bool
+	}{
+		"pending":   {status: corev1.PodStatus{Phase: corev1.PodPending}, running: true},
+		"running":   {status: corev1.PodStatus{Phase: corev1.PodRunning}, running: true},
+		"succeeded": {status: corev1.PodStatus{Phase: corev1.PodSucceeded, ContainerStatuses: []corev1.ContainerStatus{terminatedContainer("backup-maker", 0)}}, succeeded: true},
+		"failed":    {status: corev1.PodStatus{Phase: corev1.PodFailed, ContainerStatuses: []corev1.ContainerStatus{terminatedContainer("backup-maker", 1)}}, failed: true},
+		"timed out": {status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "DeadlineExceeded"}, failed: true, timeout: true},
+		"init container failed, Pod is still pending": {
+			status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{terminatedContainer("injector", 127)}},
+			failed: true,
+		},
+	} {
+		adapter := KubernetesPodResourceType{client: fake.NewSimpleClientset(createPod(tc.status)).CoreV1()}
+		health, err := adapter.GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.running, health.Running, name)
+		assert.Equal(t, tc.succeeded, health.Succeeded, name)
+		assert.Equal(t, tc.failed, health.Failed, name)
+		assert.Equal(t, tc.timeout, health.TimedOut, name)
+		assert.Equal(t, "app1-restore-x7k2p", health.Name, name)
+	}
+}
+
+// TestKubernetesPodResourceType_ExitCodeInMessage is checking that the user can see which container failed
+func TestKubernetesPodResourceType_ExitCodeInMessage(t *testing.T) {
+	adapter := KubernetesPodResourceType{client: fake.NewSimpleClientset(createPod(corev1.PodStatus{
+		Phase:             corev1.PodFailed,
+		ContainerStatuses: []corev1.ContainerStatus{terminatedContainer("backup-maker", 2)},
+	})).CoreV1()}
+
+	health, _ := adapter.GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+	assert.Contains(t, health.Message, "container 'backup-maker' exited with code 2")
+
+	_, err := KubernetesPodResourceType{client: fake.NewSimpleClientset().CoreV1()}.GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+	assert.NotNil(t, err)
+}
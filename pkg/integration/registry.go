@@ -0,0 +1,132 @@
+package integration
+
+//
+// Registry of adapters. Each adapter registers itself in init() by the GVK it is handling,
+// so adding a new integration requires only a new file in this package
+//
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Adapter is a registration of an integration with a kind of objects, that are performing or scheduling backup & restore actions
+type Adapter struct {
+	// Name is used to enable or disable the adapter with the `--integrations` flag
+	Name string
+
+	// Kind is handled by the adapter
+	Kind schema.GroupVersionKind
+
+	// Runnable tells that RequestedBackupAction can perform the action using this kind (.spec.kindType)
+	Runnable bool
+
+	// Owns are objects watched by the observers, so a change of a child object updates the status immediately
+	Owns []client.Object
+
+	// New is creating an instance of the adapter
+	New func(cfg *rest.Config) (SupportedJobResourceType, error)
+}
+
+// KindsDiscovery is telling which kinds are served by the cluster, implemented by discovery.DiscoveryInterface
+type KindsDiscovery interface {
+	ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error)
+}
+
+var registered []Adapter
+
+// Register is adding an adapter to the list of available integrations. Should be called in init()
+func Register(adapter Adapter) {
+	for _, existing := range registered {
+		if existing.Name == adapter.Name || existing.Kind == adapter.Kind {
+			panic(errors.Errorf("integration '%s' (%s) is already registered", adapter.Name, adapter.Kind))
+		}
+	}
+	registered = append(registered, adapter)
+}
+
+// AdapterNames are names of all registered adapters, in order of registration
+func AdapterNames() []string {
+	names := make([]string, 0, len(registered))
+	for _, adapter := range registered {
+		names = append(names, adapter.Name)
+	}
+	return names
+}
+
+// NewAllSupportedJobResourceTypes is creating enabled adapters. Adapters of kinds that are not served by the cluster
+// (e.g. Argo Workflows CRD is not installed) are skipped
+func NewAllSupportedJobResourceTypes(cfg *rest.Config, discovery KindsDiscovery, enabled []string, logger *logrus.Entry) (AllSupportedJobResourceTypes, error) {
+	return newAllSupportedJobResourceTypes(registered, cfg, discovery, enabled, logger)
+}
+
+func newAllSupportedJobResourceTypes(adapters []Adapter, cfg *rest.Config, discovery KindsDiscovery, enabled []string, logger *logrus.Entry) (AllSupportedJobResourceTypes, error) {
+	all := AllSupportedJobResourceTypes{adapters: make(map[schema.GroupVersionKind]SupportedJobResourceType)}
+	isEnabled := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		isEnabled[name] = true
+	}
+
+	for _, adapter := range adapters {
+		if !isEnabled[adapter.Name] {
+			logger.Infof("Integration '%s' is disabled", adapter.Name)
+			continue
+		}
+		delete(isEnabled, adapter.Name)
+
+		served, err := isKindServed(discovery, adapter.Kind)
+		if err != nil {
+			return AllSupportedJobResourceTypes{}, errors.Wrapf(err, "cannot check if %s is served by the cluster", adapter.Kind)
+		}
+		if !served {
+			logger.Warnf("Integration '%s' is not available - %s is not installed in the cluster", adapter.Name, adapter.Kind)
+			continue
+		}
+
+		instance, err := adapter.New(cfg)
+		if err != nil {
+			return AllSupportedJobResourceTypes{}, errors.Wrapf(err, "cannot create integration '%s'", adapter.Name)
+		}
+		all.adapters[adapter.Kind] = instance
+		all.owns = append(all.owns, adapter.Owns...)
+		if adapter.Runnable {
+			all.runnable = append(all.runnable, adapter.Kind)
+		}
+		logger.Infof("Integration '%s' is enabled", adapter.Name)
+	}
+
+	for name := range isEnabled {
+		return AllSupportedJobResourceTypes{}, errors.Errorf("unknown integration '%s', available: %v", name, AdapterNames())
+	}
+	return all, nil
+}
+
+// isKindServed is asking the API server if the kind is known, e.g. if a CRD is installed
+func isKindServed(discovery KindsDiscovery, gvk schema.GroupVersionKind) (bool, error) {
+	resources, err := discovery.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == gvk.Kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// newOwnedUnstructured is an object to watch for kinds, that have no Go types in this project, e.g. CRDs of Argo or Tekton
+func newOwnedUnstructured(gvk schema.GroupVersionKind) client.Object {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
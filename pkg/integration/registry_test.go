@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+type fakeAdapter struct {
+	status v1alpha1.JobHealthStatus
+}
+
+func (fa fakeAdapter) GetScheduledJobHealthStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.JobHealthStatus, error) {
+	return fa.status, nil
+}
+
+func createFakeAdapter(name string, gvk schema.GroupVersionKind) Adapter {
+	return Adapter{
+		Name: name,
+		Kind: gvk,
+		Owns: []client.Object{newOwnedUnstructured(gvk)},
+		New: func(cfg *rest.Config) (SupportedJobResourceType, error) {
+			return fakeAdapter{status: v1alpha1.JobHealthStatus{Message: name}}, nil
+		},
+	}
+}
+
+func createFakeRunnableAdapter(name string, gvk schema.GroupVersionKind) Adapter {
+	adapter := createFakeAdapter(name, gvk)
+	adapter.Runnable = true
+	return adapter
+}
+
+// createDiscovery is a cluster, that has only Kubernetes Jobs and Argo Workflows, but no Tekton
+func createDiscovery() KindsDiscovery {
+	return &discoveryfake.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "jobs", Kind: "Job"}}},
+		{GroupVersion: "argoproj.io/v1alpha1", APIResources: []metav1.APIResource{{Name: "workflows", Kind: "Workflow"}}},
+	}}}
+}
+
+var (
+	testJobAdapter     = createFakeAdapter("job", schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"})
+	testArgoAdapter    = createFakeRunnableAdapter("argo-workflow", argoWorkflowGVK)
+	testTaskRunAdapter = createFakeAdapter("tekton-taskrun", tektonTaskRunGVK)
+)
+
+// TestNewAllSupportedJobResourceTypes_SkipsKindsNotServedByCluster is checking that e.g. Tekton adapter is not enabled, when Tekton is not installed
+func TestNewAllSupportedJobResourceTypes_SkipsKindsNotServedByCluster(t *testing.T) {
+	all, err := newAllSupportedJobResourceTypes([]Adapter{testJobAdapter, testArgoAdapter, testTaskRunAdapter}, &rest.Config{},
+		createDiscovery(), []string{"job", "argo-workflow", "tekton-taskrun"}, logrus.NewEntry(logrus.New()))
+
+	assert.Nil(t, err)
+	assert.Len(t, all.GetOwnedObjects(), 2)
+	assert.Equal(t, []schema.GroupVersionKind{argoWorkflowGVK}, all.GetRunnableKinds())
+
+	status, err := all.GetScheduledJobHealthStatus(context.TODO(), argoWorkflowGVK, "aaa", "team-a")
+	assert.Nil(t, err)
+	assert.Equal(t, "argo-workflow", status.Message)
+
+	_, err = all.GetScheduledJobHealthStatus(context.TODO(), tektonTaskRunGVK, "aaa", "team-a")
+	assert.Equal(t, ErrorUnrecognizedResourceType, err.Error())
+}
+
+// TestNewAllSupportedJobResourceTypes_DisabledByFlag is checking that only adapters listed in `--integrations` are created
+func TestNewAllSupportedJobResourceTypes_DisabledByFlag(t *testing.T) {
+	all, err := newAllSupportedJobResourceTypes([]Adapter{testJobAdapter, testArgoAdapter}, &rest.Config{},
+		createDiscovery(), []string{"job"}, logrus.NewEntry(logrus.New()))
+
+	assert.Nil(t, err)
+	assert.Empty(t, all.GetRunnableKinds(), "Expected that a disabled Argo Workflow is not runnable")
+	_, err = all.GetScheduledJobHealthStatus(context.TODO(), argoWorkflowGVK, "aaa", "team-a")
+	assert.Equal(t, ErrorUnrecognizedResourceType, err.Error())
+
+	_, err = all.GetCronJobStatus(context.TODO(), "aaa", "team-a")
+	assert.NotNil(t, err)
+}
+
+func TestNewAllSupportedJobResourceTypes_UnknownName(t *testing.T) {
+	_, err := newAllSupportedJobResourceTypes([]Adapter{testJobAdapter}, &rest.Config{},
+		createDiscovery(), []string{"job", "jenkins"}, logrus.NewEntry(logrus.New()))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown integration 'jenkins'")
+}
+
+// TestRegisteredAdapters is checking that built-in adapters are registered and all of them are enabled by default
+func TestRegisteredAdapters(t *testing.T) {
+	assert.Equal(t, []string{"argo-workflow", "cronjob", "job", "pod", "tekton-taskrun", "tekton-pipelinerun"}, AdapterNames())
+	for _, adapter := range registered {
+		assert.Equal(t, adapter.Kind != cronJobGVK, adapter.Runnable, "Expected that only a CronJob cannot perform the action")
+	}
+}
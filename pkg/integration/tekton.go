@@ -0,0 +1,137 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// tektonTimeoutReasons are reasons of the `Succeeded=False` condition, set by Tekton when the run exceeded its timeout
+var tektonTimeoutReasons = map[string]bool{
+	"TaskRunTimeout":     true,
+	"PipelineRunTimeout": true,
+}
+
+var (
+	tektonTaskRunGVK     = schema.GroupVersionKind{Group: "tekton.dev", Version: "v1", Kind: "TaskRun"}
+	tektonPipelineRunGVK = schema.GroupVersionKind{Group: "tekton.dev", Version: "v1", Kind: "PipelineRun"}
+)
+
+func init() {
+	Register(Adapter{
+		Name:     "tekton-taskrun",
+		Kind:     tektonTaskRunGVK,
+		Runnable: true,
+		Owns:     []client.Object{newOwnedUnstructured(tektonTaskRunGVK)},
+		New: func(cfg *rest.Config) (SupportedJobResourceType, error) {
+			return NewTektonTaskRunResourceType(cfg)
+		},
+	})
+	Register(Adapter{
+		Name:     "tekton-pipelinerun",
+		Kind:     tektonPipelineRunGVK,
+		Runnable: true,
+		Owns:     []client.Object{newOwnedUnstructured(tektonPipelineRunGVK)},
+		New: func(cfg *rest.Config) (SupportedJobResourceType, error) {
+			return NewTektonPipelineRunResourceType(cfg)
+		},
+	})
+}
+
+// TektonRunResourceType is following Tekton `TaskRun` or `PipelineRun`. Both are reporting the result
+// in the `Succeeded` condition: True - succeeded, False - failed, Unknown or missing - still running
+type TektonRunResourceType struct {
+	client dynamic.Interface
+	gvk    schema.GroupVersionKind
+	gvr    schema.GroupVersionResource
+}
+
+func (tr TektonRunResourceType) GetScheduledJobHealthStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.JobHealthStatus, error) {
+	list, err := tr.client.Resource(tr.gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: v1alpha1.LabelTrackingId + "=" + trackingId})
+	if err != nil {
+		return v1alpha1.JobHealthStatus{}, errors.Wrapf(err, "cannot list Tekton %s", tr.gvk.Kind)
+	}
+	if len(list.Items) == 0 {
+		return v1alpha1.JobHealthStatus{}, errors.Errorf("cannot find any %s labelled with %s=%s", tr.gvk.Kind, v1alpha1.LabelTrackingId, trackingId)
+	}
+
+	running := false
+	var name string
+	for _, run := range list.Items {
+		status, reason, message := getSucceededCondition(run)
+		if status == metav1.ConditionFalse {
+			return v1alpha1.JobHealthStatus{
+				ChildReference: v1alpha1.ChildReference{
+					APIVersion: tr.gvk.GroupVersion().String(),
+					Kind:       tr.gvk.Kind,
+					TrackingId: trackingId,
+					Name:       run.GetName(),
+				},
+				Message:  fmt.Sprintf("%s %s/%s failed (%s): %s", tr.gvk.Kind, run.GetNamespace(), run.GetName(), reason, message),
+				Failed:   true,
+				TimedOut: tektonTimeoutReasons[reason],
+			}, nil
+		}
+		if status != metav1.ConditionTrue {
+			running = true
+		}
+		name = run.GetName()
+	}
+
+	return v1alpha1.JobHealthStatus{
+		ChildReference: v1alpha1.ChildReference{
+			APIVersion: tr.gvk.GroupVersion().String(),
+			Kind:       tr.gvk.Kind,
+			TrackingId: trackingId,
+			Name:       name,
+		},
+		Message:   fmt.Sprintf("All %s labelled with %s=%s succeed in %s namespace", tr.gvk.Kind, v1alpha1.LabelTrackingId, trackingId, namespace),
+		Succeeded: !running,
+		Running:   running,
+	}, nil
+}
+
+// getSucceededCondition is returning status, reason and message of the `Succeeded` condition. Empty status, when there is no such condition yet
+func getSucceededCondition(run unstructured.Unstructured) (metav1.ConditionStatus, string, string) {
+	conditions, _, _ := unstructured.NestedSlice(run.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Succeeded" {
+			continue
+		}
+		status, _, _ := unstructured.NestedString(condition, "status")
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		return metav1.ConditionStatus(status), reason, message
+	}
+	return "", "", ""
+}
+
+// NewTektonTaskRunResourceType is creating an instance of a Tekton `Kind: TaskRun` checker
+func NewTektonTaskRunResourceType(cfg *rest.Config) (TektonRunResourceType, error) {
+	return newTektonRunResourceType(cfg, tektonTaskRunGVK, "taskruns")
+}
+
+// NewTektonPipelineRunResourceType is creating an instance of a Tekton `Kind: PipelineRun` checker
+func NewTektonPipelineRunResourceType(cfg *rest.Config) (TektonRunResourceType, error) {
+	return newTektonRunResourceType(cfg, tektonPipelineRunGVK, "pipelineruns")
+}
+
+func newTektonRunResourceType(cfg *rest.Config, gvk schema.GroupVersionKind, resource string) (TektonRunResourceType, error) {
+	dynamicClient, clErr := dynamic.NewForConfig(cfg)
+	if clErr != nil {
+		return TektonRunResourceType{}, errors.Wrap(clErr, "cannot create dynamic client")
+	}
+	return TektonRunResourceType{
+		client: dynamicClient,
+		gvk:    gvk,
+		gvr:    gvk.GroupVersion().WithResource(resource),
+	}, nil
+}
@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"testing"
+)
+
+func createTaskRun(name string, conditions ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1",
+		"kind":       "TaskRun",
+		"metadata": map[string]interface{}{
+			"name": name, "namespace": "team-a",
+			"labels": map[string]interface{}{v1alpha1.LabelTrackingId: "aaa"},
+		},
+		"status": map[string]interface{}{"conditions": conditions},
+	}}
+}
+
+func succeededCondition(status string, reason string) interface{} {
+	return map[string]interface{}{"type": "Succeeded", "status": status, "reason": reason, "message": "..."}
+}
+
+func createTektonAdapter(objects ...runtime.Object) TektonRunResourceType {
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1", Resource: "taskruns"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "TaskRunList"}, objects...)
+	return TektonRunResourceType{client: client, gvk: tektonTaskRunGVK, gvr: gvr}
+}
+
+// TestTektonRunResourceType_SucceededCondition is checking that the `Succeeded` condition is translated into the health status
+func TestTektonRunResourceType_SucceededCondition(t *testing.T) {
+	for _, tc := range []struct {
+		condition                           interface{}
+		running, succeeded, failed, timeout bool
+	}{
+		{condition: nil, running: true},
+		{condition: succeededCondition("Unknown", "Running"), running: true},
+		{condition: succeededCondition("True", "Succeeded"), succeeded: true},
+		{condition: succeededCondition("False", "Failed"), failed: true},
+		{condition: succeededCondition("False", "TaskRunTimeout"), failed: true, timeout: true},
+	} {
+		var conditions []interface{}
+		if tc.condition != nil {
+			conditions = append(conditions, tc.condition)
+		}
+		health, err := createTektonAdapter(createTaskRun("app1-restore-x7k2p", conditions...)).
+			GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.running, health.Running, "condition: %v", tc.condition)
+		assert.Equal(t, tc.succeeded, health.Succeeded, "condition: %v", tc.condition)
+		assert.Equal(t, tc.failed, health.Failed, "condition: %v", tc.condition)
+		assert.Equal(t, tc.timeout, health.TimedOut, "condition: %v", tc.condition)
+		assert.Equal(t, "tekton.dev/v1", health.APIVersion)
+		assert.Equal(t, "TaskRun", health.Kind)
+	}
+}
+
+// TestTektonRunResourceType_NotFound is checking that a missing TaskRun is reported as an error, not as a success
+func TestTektonRunResourceType_NotFound(t *testing.T) {
+	_, err := createTektonAdapter().GetScheduledJobHealthStatus(context.TODO(), "aaa", "team-a")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot find any TaskRun")
+}
@@ -5,37 +5,50 @@ import (
 	"github.com/pkg/errors"
 	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const ErrorUnrecognizedResourceType = "unrecognized resource type"
 
 type SupportedJobResourceType interface {
-	MatchesGVK(gvk schema.GroupVersionKind) bool
 	GetScheduledJobHealthStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.JobHealthStatus, error)
 }
 
+// cronJobStatusReader is implemented by adapters, that are able to tell the history of runs of a CronJob
+type cronJobStatusReader interface {
+	GetCronJobStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.CronJobStatus, error)
+}
+
 // AllSupportedJobResourceTypes is a bridge/interface to all adapters implementing various job types
 // instead of asking multiple adapters you ask a single service that aggregates all of them
 type AllSupportedJobResourceTypes struct {
-	cfg       *rest.Config
-	supported []SupportedJobResourceType
+	adapters map[schema.GroupVersionKind]SupportedJobResourceType
+	owns     []client.Object
+	runnable []schema.GroupVersionKind
 }
 
 func (all *AllSupportedJobResourceTypes) GetScheduledJobHealthStatus(ctx context.Context, gvk schema.GroupVersionKind, trackingId string, namespace string) (v1alpha1.JobHealthStatus, error) {
-	for _, resource := range all.supported {
-		if resource.MatchesGVK(gvk) {
-			return resource.GetScheduledJobHealthStatus(ctx, trackingId, namespace)
-		}
+	if adapter, exists := all.adapters[gvk]; exists {
+		return adapter.GetScheduledJobHealthStatus(ctx, trackingId, namespace)
 	}
 	return v1alpha1.JobHealthStatus{}, errors.New(ErrorUnrecognizedResourceType)
 }
 
-func NewAllSupportedJobResourceTypes(cfg *rest.Config) AllSupportedJobResourceTypes {
-	return AllSupportedJobResourceTypes{
-		cfg: cfg,
-		supported: []SupportedJobResourceType{
-			NewKubernetesJobResourceType(cfg),
-		},
+// GetCronJobStatus is returning the history of runs of a CronJob, that is still kept by Kubernetes
+func (all *AllSupportedJobResourceTypes) GetCronJobStatus(ctx context.Context, trackingId string, namespace string) (v1alpha1.CronJobStatus, error) {
+	if reader, ok := all.adapters[cronJobGVK].(cronJobStatusReader); ok {
+		return reader.GetCronJobStatus(ctx, trackingId, namespace)
 	}
+	return v1alpha1.CronJobStatus{}, errors.Errorf("integration with %s is not enabled", cronJobGVK)
+}
+
+// GetOwnedObjects are kinds of objects, that the observers should watch
+func (all *AllSupportedJobResourceTypes) GetOwnedObjects() []client.Object {
+	return all.owns
+}
+
+// GetRunnableKinds are kinds, that RequestedBackupAction can use to perform the action (.spec.kindType).
+// Only enabled adapters of kinds served by the cluster are taken into account
+func (all *AllSupportedJobResourceTypes) GetRunnableKinds() []schema.GroupVersionKind {
+	return all.runnable
 }
@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	LabelNamespace       = "namespace"
+	LabelScheduledBackup = "scheduledbackup"
+	LabelOperation       = "operation"
+	LabelTemplate        = "template"
+)
+
+var labelNames = []string{LabelNamespace, LabelScheduledBackup, LabelOperation, LabelTemplate}
+
+var (
+	LastSuccessTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "backup_maker_last_success_timestamp_seconds",
+		Help: "Unix timestamp of the last successfully finished backup or restore",
+	}, labelNames)
+
+	LastFailureTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "backup_maker_last_failure_timestamp_seconds",
+		Help: "Unix timestamp of the last failed or timed out backup or restore",
+	}, labelNames)
+
+	RunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "backup_maker_run_duration_seconds",
+		Help: "Duration of finished backup and restore runs",
+		// from 30 seconds up to ~4 hours
+		Buckets: prometheus.ExponentialBuckets(30, 2, 10),
+	}, labelNames)
+
+	ConsecutiveFailures = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "backup_maker_consecutive_failures",
+		Help: "Number of runs that did not succeed since the last successful run",
+	}, labelNames)
+
+	RenderErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "backup_maker_render_errors_total",
+		Help: "Number of failures while rendering the Backup Maker Generator templates",
+	}, labelNames)
+
+	ApplyErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "backup_maker_apply_errors_total",
+		Help: "Number of failures while applying rendered objects to the cluster",
+	}, labelNames)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(LastSuccessTimestamp, LastFailureTimestamp, RunDuration, ConsecutiveFailures, RenderErrors, ApplyErrors)
+}
+
+// Labels are identifying metrics of a single operation of a ScheduledBackup. RequestedBackupAction is reported as its ScheduledBackup
+func Labels(backup *v1alpha1.ScheduledBackup, operation string) prometheus.Labels {
+	return prometheus.Labels{
+		LabelNamespace:       backup.Namespace,
+		LabelScheduledBackup: backup.Name,
+		LabelOperation:       operation,
+		LabelTemplate:        backup.Spec.TemplateRef.Name,
+	}
+}
+
+// Run is a finished backup or restore, e.g. a Job spawned by a CronJob or an attempt of RequestedBackupAction
+type Run struct {
+	Succeeded      bool
+	StartTime      time.Time
+	CompletionTime time.Time
+}
+
+// recorded keeps the completion time of the newest recorded run per labels set, so the observers could report the same runs
+// again at every iteration without counting them twice
+var recorded = struct {
+	sync.Mutex
+	lastCompletion map[string]time.Time
+	labels         map[string]prometheus.Labels
+}{lastCompletion: make(map[string]time.Time), labels: make(map[string]prometheus.Labels)}
+
+// templates are remembering the template of each ScheduledBackup, so the series of a replaced template could be deleted
+var templates = struct {
+	sync.Mutex
+	byBackup map[string]string
+}{byBackup: make(map[string]string)}
+
+// TrackTemplate is deleting series of the previous template, when .spec.templateRef of the ScheduledBackup was changed.
+// Those series would be never updated again, and could keep firing alerts
+func TrackTemplate(backup *v1alpha1.ScheduledBackup) {
+	templates.Lock()
+	defer templates.Unlock()
+	key := backup.Namespace + "/" + backup.Name
+	previous, known := templates.byBackup[key]
+	templates.byBackup[key] = backup.Spec.TemplateRef.Name
+
+	if known && previous != backup.Spec.TemplateRef.Name {
+		deleteSeries(prometheus.Labels{LabelNamespace: backup.Namespace, LabelScheduledBackup: backup.Name, LabelTemplate: previous})
+	}
+}
+
+// Forget is deleting all series of a ScheduledBackup, that no longer exists
+func Forget(namespace string, name string) {
+	templates.Lock()
+	defer templates.Unlock()
+	delete(templates.byBackup, namespace+"/"+name)
+	deleteSeries(prometheus.Labels{LabelNamespace: namespace, LabelScheduledBackup: name})
+}
+
+// deleteSeries is deleting series matching given labels from all metrics, together with the recorded runs
+func deleteSeries(labels prometheus.Labels) {
+	for _, vec := range []*prometheus.MetricVec{LastSuccessTimestamp.MetricVec, LastFailureTimestamp.MetricVec, RunDuration.MetricVec,
+		ConsecutiveFailures.MetricVec, RenderErrors.MetricVec, ApplyErrors.MetricVec} {
+		vec.DeletePartialMatch(labels)
+	}
+
+	recorded.Lock()
+	defer recorded.Unlock()
+	for key, keyLabels := range recorded.labels {
+		if matches(keyLabels, labels) {
+			delete(recorded.lastCompletion, key)
+			delete(recorded.labels, key)
+		}
+	}
+}
+
+func matches(labels prometheus.Labels, partial prometheus.Labels) bool {
+	for name, value := range partial {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// RecordRuns is updating the metrics with runs finished after the previously recorded one
+func RecordRuns(labels prometheus.Labels, runs []Run) {
+	sorted := append([]Run{}, runs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CompletionTime.Before(sorted[j].CompletionTime)
+	})
+
+	recorded.Lock()
+	defer recorded.Unlock()
+	key := labels[LabelNamespace] + "/" + labels[LabelScheduledBackup] + "/" + labels[LabelOperation] + "/" + labels[LabelTemplate]
+
+	for _, run := range sorted {
+		if run.CompletionTime.IsZero() || !run.CompletionTime.After(recorded.lastCompletion[key]) {
+			continue
+		}
+		recorded.lastCompletion[key] = run.CompletionTime
+		recorded.labels[key] = labels
+
+		if !run.StartTime.IsZero() {
+			RunDuration.With(labels).Observe(run.CompletionTime.Sub(run.StartTime).Seconds())
+		}
+		if run.Succeeded {
+			LastSuccessTimestamp.With(labels).Set(float64(run.CompletionTime.Unix()))
+			ConsecutiveFailures.With(labels).Set(0)
+			continue
+		}
+		LastFailureTimestamp.With(labels).Set(float64(run.CompletionTime.Unix()))
+		ConsecutiveFailures.With(labels).Inc()
+	}
+}
+
+// RunsFromCronJobStatus is converting the finished runs of a CronJob
+func RunsFromCronJobStatus(status *v1alpha1.CronJobStatus) []Run {
+	if status == nil {
+		return nil
+	}
+	runs := make([]Run, 0, len(status.LastRuns))
+	for _, run := range status.LastRuns {
+		if !run.Outcome.IsFinished() || run.CompletionTime == nil {
+			continue
+		}
+		converted := Run{Succeeded: run.Outcome == v1alpha1.RunSucceeded, CompletionTime: run.CompletionTime.Time}
+		if run.StartTime != nil {
+			converted.StartTime = run.StartTime.Time
+		}
+		runs = append(runs, converted)
+	}
+	return runs
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestRecordRuns(t *testing.T) {
+	backup := &v1alpha1.ScheduledBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "team-a"},
+		Spec:       v1alpha1.ScheduledBackupSpec{TemplateRef: v1alpha1.TemplateSpec{Kind: "internal", Name: "pg14"}},
+	}
+	labels := Labels(backup, "backup")
+	at := func(hour int) time.Time {
+		return time.Date(2023, 1, 1, hour, 0, 0, 0, time.UTC)
+	}
+
+	RecordRuns(labels, []Run{
+		{Succeeded: false, StartTime: at(3).Add(-time.Minute), CompletionTime: at(3)},
+		{Succeeded: true, StartTime: at(1).Add(-time.Minute), CompletionTime: at(1)},
+		{Succeeded: false, StartTime: at(2).Add(-time.Minute), CompletionTime: at(2)},
+	})
+	assert.Equal(t, float64(at(1).Unix()), testutil.ToFloat64(LastSuccessTimestamp.With(labels)))
+	assert.Equal(t, float64(at(3).Unix()), testutil.ToFloat64(LastFailureTimestamp.With(labels)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(ConsecutiveFailures.With(labels)))
+
+	// the same runs are reported again by the observer at every iteration
+	RecordRuns(labels, []Run{
+		{Succeeded: false, StartTime: at(3).Add(-time.Minute), CompletionTime: at(3)},
+		{Succeeded: false, StartTime: at(4).Add(-time.Minute), CompletionTime: at(4)},
+	})
+	assert.Equal(t, float64(3), testutil.ToFloat64(ConsecutiveFailures.With(labels)))
+	assert.Equal(t, 1, testutil.CollectAndCount(RunDuration, "backup_maker_run_duration_seconds"))
+
+	RecordRuns(labels, []Run{{Succeeded: true, StartTime: at(5).Add(-time.Minute), CompletionTime: at(5)}})
+	assert.Equal(t, float64(at(5).Unix()), testutil.ToFloat64(LastSuccessTimestamp.With(labels)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(ConsecutiveFailures.With(labels)))
+}
+
+func TestRunsFromCronJobStatus(t *testing.T) {
+	started := metav1.NewTime(time.Date(2023, 1, 1, 2, 0, 0, 0, time.UTC))
+	finished := metav1.NewTime(started.Add(time.Minute * 5))
+
+	runs := RunsFromCronJobStatus(&v1alpha1.CronJobStatus{LastRuns: []v1alpha1.CronJobRun{
+		{JobName: "app1-backup-3", Outcome: v1alpha1.RunRunning, StartTime: &started},
+		{JobName: "app1-backup-2", Outcome: v1alpha1.RunTimedOut, StartTime: &started, CompletionTime: &finished},
+	}})
+
+	assert.Equal(t, []Run{{Succeeded: false, StartTime: started.Time, CompletionTime: finished.Time}}, runs)
+	assert.Nil(t, RunsFromCronJobStatus(nil))
+}
+
+func TestForget(t *testing.T) {
+	createBackup := func(name string) *v1alpha1.ScheduledBackup {
+		return &v1alpha1.ScheduledBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-b"},
+			Spec:       v1alpha1.ScheduledBackupSpec{TemplateRef: v1alpha1.TemplateSpec{Kind: "internal", Name: "pg14"}},
+		}
+	}
+	finished := time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC)
+	for _, operation := range []string{"backup", "restore"} {
+		RecordRuns(Labels(createBackup("app1"), operation), []Run{{Succeeded: true, StartTime: finished.Add(-time.Minute), CompletionTime: finished}})
+	}
+	RecordRuns(Labels(createBackup("app2"), "backup"), []Run{{Succeeded: true, StartTime: finished.Add(-time.Minute), CompletionTime: finished}})
+	before := testutil.CollectAndCount(LastSuccessTimestamp)
+
+	Forget("team-b", "app1")
+
+	assert.Equal(t, before-2, testutil.CollectAndCount(LastSuccessTimestamp), "Expected that both operations of app1 are deleted")
+	assert.Equal(t, float64(finished.Unix()), testutil.ToFloat64(LastSuccessTimestamp.With(Labels(createBackup("app2"), "backup"))))
+
+	// a ScheduledBackup created again with the same name is reporting its runs from scratch
+	RecordRuns(Labels(createBackup("app1"), "backup"), []Run{{Succeeded: false, StartTime: finished.Add(-time.Minute), CompletionTime: finished}})
+	assert.Equal(t, float64(1), testutil.ToFloat64(ConsecutiveFailures.With(Labels(createBackup("app1"), "backup"))))
+}
+
+func TestTrackTemplate(t *testing.T) {
+	backup := &v1alpha1.ScheduledBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "team-c"},
+		Spec:       v1alpha1.ScheduledBackupSpec{TemplateRef: v1alpha1.TemplateSpec{Kind: "internal", Name: "pg14"}},
+	}
+	finished := time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC)
+	TrackTemplate(backup)
+	RecordRuns(Labels(backup, "backup"), []Run{{Succeeded: true, StartTime: finished.Add(-time.Minute), CompletionTime: finished}})
+	before := testutil.CollectAndCount(LastSuccessTimestamp)
+
+	// the same template is kept
+	TrackTemplate(backup)
+	assert.Equal(t, before, testutil.CollectAndCount(LastSuccessTimestamp))
+
+	backup.Spec.TemplateRef.Name = "pg15"
+	TrackTemplate(backup)
+	assert.Equal(t, before-1, testutil.CollectAndCount(LastSuccessTimestamp), "Expected that series of 'pg14' are deleted")
+}
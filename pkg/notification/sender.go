@@ -0,0 +1,182 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Notification is a result of a backup or restore, sent to NotificationChannels
+type Notification struct {
+	// Kind of the object that performed the operation: ScheduledBackup or RequestedBackupAction
+	Kind            string              `json:"kind"`
+	Namespace       string              `json:"namespace"`
+	Name            string              `json:"name"`
+	ScheduledBackup string              `json:"scheduledBackup"`
+	Operation       string              `json:"operation"`
+	Outcome         v1alpha1.RunOutcome `json:"outcome"`
+	JobName         string              `json:"jobName,omitempty"`
+	StartTime       *time.Time          `json:"startTime,omitempty"`
+	CompletionTime  *time.Time          `json:"completionTime,omitempty"`
+	Message         string              `json:"message,omitempty"`
+}
+
+// Sender is delivering notifications to NotificationChannels referenced by ScheduledBackups and RequestedBackupActions
+type Sender struct {
+	Cache      client.Reader
+	HTTPClient *http.Client
+}
+
+// NewSender creates a Sender reading NotificationChannels and Secrets from given reader, usually the manager's cache
+func NewSender(cache client.Reader) *Sender {
+	return &Sender{Cache: cache, HTTPClient: &http.Client{}}
+}
+
+// Send is delivering the notification to all referenced channels, that are interested in the outcome.
+// A failed channel does not stop the delivery to other channels
+func (s *Sender) Send(ctx context.Context, logger *logrus.Entry, namespace string, refs []v1alpha1.NotificationChannelRefSpec, notification Notification) error {
+	var failures []string
+	for _, ref := range refs {
+		if err := s.sendTo(ctx, logger, namespace, ref.Name, notification); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Errorf("cannot notify: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (s *Sender) sendTo(ctx context.Context, logger *logrus.Entry, namespace string, name string, notification Notification) error {
+	channel := v1alpha1.NotificationChannel{}
+	if err := s.Cache.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &channel); err != nil {
+		return errors.Wrapf(err, "cannot fetch NotificationChannel '%s'", name)
+	}
+	if !channel.Spec.IsNotifiedOn(notification.Outcome) {
+		logger.Debugf("NotificationChannel '%s' is not interested in '%s' outcome", name, notification.Outcome)
+		return nil
+	}
+	webhook := &channel.Spec.Webhook
+
+	body, err := RenderBody(webhook.Body, notification)
+	if err != nil {
+		return errors.Wrapf(err, "cannot render body of NotificationChannel '%s'", name)
+	}
+	var signature string
+	if webhook.HMAC != nil {
+		secret := v1.Secret{}
+		if getErr := s.Cache.Get(ctx, client.ObjectKey{Name: webhook.HMAC.SecretName, Namespace: namespace}, &secret); getErr != nil {
+			return errors.Wrapf(getErr, "cannot fetch HMAC key of NotificationChannel '%s'", name)
+		}
+		key, exists := secret.Data[webhook.HMAC.Key]
+		if !exists {
+			return errors.Errorf("key '%s' not found in Secret '%s' of NotificationChannel '%s'", webhook.HMAC.Key, webhook.HMAC.SecretName, name)
+		}
+		signature = Sign(body, key)
+	}
+
+	if err := s.deliver(ctx, logger, webhook, body, signature); err != nil {
+		return errors.Wrapf(err, "cannot deliver notification to NotificationChannel '%s'", name)
+	}
+	logger.Infof("Notified NotificationChannel '%s' about '%s' of '%s'", name, notification.Outcome, notification.Name)
+	return nil
+}
+
+// deliver is sending the body with retries. Only network errors, 5xx and 429 responses are retried
+func (s *Sender) deliver(ctx context.Context, logger *logrus.Entry, webhook *v1alpha1.WebhookSpec, body []byte, signature string) error {
+	var lastErr error
+	for attempt := 1; attempt <= webhook.GetMaxAttempts(); attempt++ {
+		if attempt > 1 {
+			delay := webhook.BackoffFor(attempt)
+			logger.Warnf("Notification attempt %d failed: %s, retrying in %s", attempt-1, lastErr.Error(), delay)
+			select {
+			case <-ctx.Done():
+				return errors.Wrap(lastErr, "delivery interrupted")
+			case <-time.After(delay):
+			}
+		}
+		retryable, err := s.post(ctx, webhook, body, signature)
+		if err == nil {
+			return nil
+		}
+		if !retryable {
+			return err
+		}
+		lastErr = err
+	}
+	return errors.Wrapf(lastErr, "gave up after %d attempts", webhook.GetMaxAttempts())
+}
+
+func (s *Sender) post(ctx context.Context, webhook *v1alpha1.WebhookSpec, body []byte, signature string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhook.GetTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "cannot create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+	if signature != "" {
+		req.Header.Set(webhook.HMAC.GetHeader(), signature)
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, errors.Errorf("%s responded with %s", webhook.URL, resp.Status)
+}
+
+// RenderBody is rendering the JSON document. An empty template means the whole notification encoded as JSON
+func RenderBody(bodyTemplate string, notification Notification) ([]byte, error) {
+	if bodyTemplate == "" {
+		return json.Marshal(notification)
+	}
+	tpl, err := template.New("body").Funcs(template.FuncMap{
+		"toJson": func(v interface{}) (string, error) {
+			encoded, marshalErr := json.Marshal(v)
+			return string(encoded), marshalErr
+		},
+	}).Option("missingkey=error").Parse(bodyTemplate)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse template")
+	}
+	var body bytes.Buffer
+	if execErr := tpl.Execute(&body, notification); execErr != nil {
+		return nil, errors.Wrap(execErr, "cannot execute template")
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, errors.Errorf("rendered body is not a valid JSON: %s", body.String())
+	}
+	return body.Bytes(), nil
+}
+
+// Sign is calculating HMAC-SHA256 of the body in format "sha256=<hex>"
+func Sign(body []byte, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
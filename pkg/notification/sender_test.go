@@ -0,0 +1,158 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func createNotification() Notification {
+	completed := time.Date(2023, 1, 1, 2, 5, 0, 0, time.UTC)
+	return Notification{
+		Kind:            "RequestedBackupAction",
+		Namespace:       "team-a",
+		Name:            "app1-restore",
+		ScheduledBackup: "app1",
+		Operation:       "restore",
+		Outcome:         v1alpha1.RunFailed,
+		JobName:         "app1-restore-x7k2p",
+		CompletionTime:  &completed,
+		Message:         `Job has reached the specified backoff limit (exit code 1, "psql" failed)`,
+	}
+}
+
+func createSender(t *testing.T, webhook v1alpha1.WebhookSpec, objects ...client.Object) *Sender {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	assert.Nil(t, v1.AddToScheme(scheme))
+
+	channel := &v1alpha1.NotificationChannel{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-chat", Namespace: "team-a"},
+		Spec:       v1alpha1.NotificationChannelSpec{Webhook: webhook},
+	}
+	return NewSender(fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, channel)...).Build())
+}
+
+var teamChat = []v1alpha1.NotificationChannelRefSpec{{Name: "team-chat"}}
+
+func TestRenderBody(t *testing.T) {
+	// the whole notification by default
+	body, err := RenderBody("", createNotification())
+	assert.Nil(t, err)
+	decoded := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, "Failed", decoded["outcome"])
+	assert.Equal(t, "2023-01-01T02:05:00Z", decoded["completionTime"])
+	assert.NotContains(t, decoded, "startTime")
+
+	// values are quoted by toJson
+	body, err = RenderBody(`{"text": {{ printf "%s of %s: %s" .Operation .Name .Message | toJson }}}`, createNotification())
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, `restore of app1-restore: Job has reached the specified backoff limit (exit code 1, "psql" failed)`, decoded["text"])
+
+	// not quoted values are breaking the JSON
+	_, err = RenderBody(`{"text": "{{ .Message }}"}`, createNotification())
+	assert.ErrorContains(t, err, "rendered body is not a valid JSON")
+
+	_, err = RenderBody(`{"text": {{ .NotExisting }}}`, createNotification())
+	assert.ErrorContains(t, err, "cannot execute template")
+}
+
+func TestSender_Send_SignedWithHMAC(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	sender := createSender(t, v1alpha1.WebhookSpec{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer xyz"},
+		HMAC:    &v1alpha1.HMACSpec{SecretName: "team-chat-webhook", Key: "hmac-key"},
+	}, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-chat-webhook", Namespace: "team-a"},
+		Data:       map[string][]byte{"hmac-key": []byte("secret")},
+	})
+
+	err := sender.Send(context.TODO(), logrus.WithContext(context.TODO()), "team-a", teamChat, createNotification())
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "Bearer xyz", received.Header.Get("Authorization"))
+	assert.Equal(t, Sign(receivedBody, []byte("secret")), received.Header.Get("X-Signature-256"))
+	assert.Contains(t, string(receivedBody), `"jobName":"app1-restore-x7k2p"`)
+}
+
+func TestSender_Send_RetriesWithBackoff(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	webhook := v1alpha1.WebhookSpec{URL: server.URL, MaxAttempts: 3, Backoff: metav1.Duration{Duration: time.Millisecond}}
+
+	assert.Nil(t, createSender(t, webhook).Send(context.TODO(), logrus.WithContext(context.TODO()), "team-a", teamChat, createNotification()))
+	assert.Equal(t, int32(3), requests)
+
+	// gives up after the last attempt
+	atomic.StoreInt32(&requests, -10)
+	err := createSender(t, webhook).Send(context.TODO(), logrus.WithContext(context.TODO()), "team-a", teamChat, createNotification())
+	assert.ErrorContains(t, err, "gave up after 3 attempts")
+	assert.Equal(t, int32(-7), requests)
+}
+
+func TestSender_Send_ClientErrorIsNotRetried(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := createSender(t, v1alpha1.WebhookSpec{URL: server.URL, MaxAttempts: 3, Backoff: metav1.Duration{Duration: time.Millisecond}}).
+		Send(context.TODO(), logrus.WithContext(context.TODO()), "team-a", teamChat, createNotification())
+
+	assert.ErrorContains(t, err, "400 Bad Request")
+	assert.Equal(t, int32(1), requests)
+}
+
+func TestSender_Send_OnlyOn(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+	sender := createSender(t, v1alpha1.WebhookSpec{URL: server.URL})
+	channel := &v1alpha1.NotificationChannel{}
+	assert.Nil(t, sender.Cache.Get(context.TODO(), client.ObjectKey{Name: "team-chat", Namespace: "team-a"}, channel))
+	channel.Spec.OnlyOn = []v1alpha1.RunOutcome{v1alpha1.RunSucceeded}
+	assert.Nil(t, sender.Cache.(client.Client).Update(context.TODO(), channel))
+
+	assert.Nil(t, sender.Send(context.TODO(), logrus.WithContext(context.TODO()), "team-a", teamChat, createNotification()))
+	assert.Equal(t, int32(0), requests)
+
+	// not existing channel does not stop notifying the others
+	err := sender.Send(context.TODO(), logrus.WithContext(context.TODO()), "team-a",
+		[]v1alpha1.NotificationChannelRefSpec{{Name: "not-existing"}, {Name: "team-chat"}}, Notification{Outcome: v1alpha1.RunSucceeded, Namespace: "team-a"})
+	assert.ErrorContains(t, err, "cannot fetch NotificationChannel 'not-existing'")
+	assert.Equal(t, int32(1), requests)
+}
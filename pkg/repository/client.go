@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version is a single uploaded version of a backup in the Backup Repository collection
+type Version struct {
+	Id            string    `json:"id"`
+	VersionNumber int       `json:"versionNumber"`
+	Filename      string    `json:"filename"`
+	Filesize      int64     `json:"filesize"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// versionsResponse is a response of GET /api/alpha/repository/collection/{collectionId}/version, the same endpoint as Backup Maker is uploading to
+type versionsResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Data    struct {
+		Versions []Version `json:"versions"`
+	} `json:"data"`
+}
+
+// Client is talking to the Backup Repository server with an access token (JWT) of the ScheduledBackup
+type Client struct {
+	URL        string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// defaultTimeout is limiting a single request, the controller should not hang on a not responding server
+const defaultTimeout = time.Second * 30
+
+func NewClient(url string, token string) *Client {
+	return &Client{URL: strings.TrimRight(url, "/"), Token: token, HTTPClient: &http.Client{Timeout: defaultTimeout}}
+}
+
+// ListVersions is returning all versions of the collection, starting from the newest one
+func (c *Client) ListVersions(ctx context.Context, collectionId string) ([]Version, error) {
+	endpoint := fmt.Sprintf("%s/api/alpha/repository/collection/%s/version", c.URL, url.PathEscape(collectionId))
+	body, err := c.request(ctx, http.MethodGet, endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot list versions of collection '%s'", collectionId)
+	}
+	decoded := versionsResponse{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse versions of collection '%s'", collectionId)
+	}
+
+	versions := decoded.Data.Versions
+	SortNewestFirst(versions)
+	return versions, nil
+}
+
+// DeleteVersion is deleting a single version from the collection, the file is no longer restorable
+func (c *Client) DeleteVersion(ctx context.Context, collectionId string, versionId string) error {
+	endpoint := fmt.Sprintf("%s/api/alpha/repository/collection/%s/version/%s", c.URL, url.PathEscape(collectionId), url.PathEscape(versionId))
+	if _, err := c.request(ctx, http.MethodDelete, endpoint); err != nil {
+		return errors.Wrapf(err, "cannot delete version '%s' of collection '%s'", versionId, collectionId)
+	}
+	return nil
+}
+
+// request is sending an authorized request and returning the body of a successful (2xx) response
+func (c *Client) request(ctx context.Context, method string, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create request")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read response")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// the server is explaining the problem in the message, e.g. a not existing collection or an expired token
+		decoded := versionsResponse{}
+		if json.Unmarshal(body, &decoded) == nil && decoded.Message != "" {
+			return nil, errors.Errorf("%s responded with %s: %s", c.URL, resp.Status, decoded.Message)
+		}
+		return nil, errors.Errorf("%s responded with %s", c.URL, resp.Status)
+	}
+	return body, nil
+}
+
+// SortNewestFirst is ordering versions by the version number, which is growing with every upload
+func SortNewestFirst(versions []Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].VersionNumber != versions[j].VersionNumber {
+			return versions[i].VersionNumber > versions[j].VersionNumber
+		}
+		return versions[i].CreatedAt.After(versions[j].CreatedAt)
+	})
+}
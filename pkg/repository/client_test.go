@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// serveVersions is a stand-in of the Backup Repository server
+func serveVersions(t *testing.T, status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/alpha/repository/collection/iwa-ait/version", r.URL.Path)
+		assert.Equal(t, "Bearer jwt", r.Header.Get("Authorization"))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestClient_ListVersions(t *testing.T) {
+	server := serveVersions(t, http.StatusOK, `{"status": true, "data": {"versions": [
+		{"id": "aaa", "versionNumber": 1, "filename": "iwa-ait-v1.tar.gz", "filesize": 1024, "createdAt": "2023-01-01T02:00:00Z"},
+		{"id": "ccc", "versionNumber": 3, "filename": "iwa-ait-v3.tar.gz", "filesize": 3072, "createdAt": "2023-01-03T02:00:00Z"},
+		{"id": "bbb", "versionNumber": 2, "filename": "iwa-ait-v2.tar.gz", "filesize": 2048, "createdAt": "2023-01-02T02:00:00Z"}
+	]}}`)
+	defer server.Close()
+
+	versions, err := NewClient(server.URL+"/", "jwt").ListVersions(context.TODO(), "iwa-ait")
+
+	assert.Nil(t, err)
+	assert.Len(t, versions, 3)
+	assert.Equal(t, []int{3, 2, 1}, []int{versions[0].VersionNumber, versions[1].VersionNumber, versions[2].VersionNumber})
+	assert.Equal(t, int64(3072), versions[0].Filesize)
+	assert.Equal(t, time.Date(2023, 1, 3, 2, 0, 0, 0, time.UTC), versions[0].CreatedAt.UTC())
+}
+
+func TestClient_ListVersions_ServerError(t *testing.T) {
+	server := serveVersions(t, http.StatusForbidden, `{"status": false, "message": "access denied to collection"}`)
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "jwt").ListVersions(context.TODO(), "iwa-ait")
+	assert.ErrorContains(t, err, "403 Forbidden: access denied to collection")
+
+	notJson := serveVersions(t, http.StatusOK, `<html>`)
+	defer notJson.Close()
+	_, err = NewClient(notJson.URL, "jwt").ListVersions(context.TODO(), "iwa-ait")
+	assert.ErrorContains(t, err, "cannot parse versions of collection 'iwa-ait'")
+}
+
+func TestNewRepositoryStatus(t *testing.T) {
+	now := time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC)
+	versions := []Version{
+		{Id: "ccc", VersionNumber: 3, Filesize: 3072, CreatedAt: now.Add(-time.Hour)},
+		{Id: "bbb", VersionNumber: 2, Filesize: 2048, CreatedAt: now.Add(-time.Hour * 25)},
+		{Id: "aaa", VersionNumber: 1, Filesize: 1024, CreatedAt: now.Add(-time.Hour * 49)},
+	}
+
+	status := NewRepositoryStatus(versions, 2, now)
+
+	assert.Equal(t, 3, status.TotalVersions)
+	assert.Len(t, status.Versions, 2)
+	assert.Equal(t, "ccc", status.Versions[0].Id)
+	assert.Equal(t, 3, status.Versions[0].Number)
+	assert.Equal(t, int64(3072), status.Versions[0].Size)
+	assert.False(t, status.IsOutdated(now.Add(time.Minute*14), time.Minute*15))
+	assert.True(t, status.IsOutdated(now.Add(time.Minute*15), time.Minute*15))
+}
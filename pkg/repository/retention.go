@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"time"
+)
+
+// RetainedVersion is a version kept by the retention, with the rules that selected it, e.g. "last", "daily"
+type RetainedVersion struct {
+	Version
+	Reasons []string
+}
+
+// RetentionPlan is splitting versions of the collection into kept and removed ones, both starting from the newest one
+type RetentionPlan struct {
+	Kept    []RetainedVersion
+	Removed []Version
+}
+
+// PlanRetention is deciding which versions (sorted with SortNewestFirst) are kept by the policy. Days, weeks and months are counted in UTC.
+// Pinned versions (e.g. "v3") are selected by restores that are not finished yet, those are kept even when expired
+func PlanRetention(versions []Version, policy v1alpha1.RetentionSpec, pinned []string, now time.Time) RetentionPlan {
+	reasons := make([][]string, len(versions))
+	if len(versions) > 0 {
+		// the collection is never emptied, e.g. when backups stopped working for longer than .maxAge
+		reasons[0] = append(reasons[0], "newest")
+	}
+	isPinned := make(map[string]bool, len(pinned))
+	for _, name := range pinned {
+		isPinned[name] = true
+	}
+	for i := 0; i < len(versions) && i < policy.KeepLast; i++ {
+		reasons[i] = append(reasons[i], "last")
+	}
+	keepNewestPerPeriod(versions, reasons, policy.KeepDaily, "daily", func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepNewestPerPeriod(versions, reasons, policy.KeepWeekly, "weekly", func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepNewestPerPeriod(versions, reasons, policy.KeepMonthly, "monthly", func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	plan := RetentionPlan{}
+	for i, version := range versions {
+		expired := policy.MaxAge != nil && version.CreatedAt.Before(now.Add(-policy.MaxAge.Duration))
+		switch {
+		case i == 0:
+		case expired:
+			reasons[i] = nil
+		case !policy.HasKeepRules():
+			reasons[i] = append(reasons[i], "maxAge")
+		}
+		if isPinned[version.GetName()] {
+			reasons[i] = append(reasons[i], "restoring")
+		}
+		if len(reasons[i]) == 0 {
+			plan.Removed = append(plan.Removed, version)
+			continue
+		}
+		plan.Kept = append(plan.Kept, RetainedVersion{Version: version, Reasons: reasons[i]})
+	}
+	return plan
+}
+
+// keepNewestPerPeriod is keeping the newest version of each of `limit` periods, that have any version
+func keepNewestPerPeriod(versions []Version, reasons [][]string, limit int, reason string, periodOf func(t time.Time) string) {
+	seen := make(map[string]bool)
+	for i, version := range versions {
+		if len(seen) >= limit {
+			return
+		}
+		period := periodOf(version.CreatedAt.UTC())
+		if seen[period] {
+			continue
+		}
+		seen[period] = true
+		reasons[i] = append(reasons[i], reason)
+	}
+}
+
+// ApplyRetention is deleting versions not kept by the policy. In dry-run mode nothing is deleted and the plan is only returned.
+// When a deletion fails, then the returned plan reflects what is left in the collection - versions that were not deleted are in .Kept
+func ApplyRetention(ctx context.Context, client *Client, collectionId string, policy v1alpha1.RetentionSpec, pinned []string, now time.Time) (RetentionPlan, error) {
+	versions, err := client.ListVersions(ctx, collectionId)
+	if err != nil {
+		return RetentionPlan{}, err
+	}
+	plan := PlanRetention(versions, policy, pinned, now)
+	if policy.DryRun {
+		return plan, nil
+	}
+
+	toRemove := plan.Removed
+	plan.Removed = nil
+	for i, version := range toRemove {
+		if err := client.DeleteVersion(ctx, collectionId, version.Id); err != nil {
+			plan.keepNotDeleted(toRemove[i:])
+			return plan, errors.Wrapf(err, "deleted %d of %d versions", len(plan.Removed), len(toRemove))
+		}
+		plan.Removed = append(plan.Removed, version)
+	}
+	return plan, nil
+}
+
+// keepNotDeleted is moving versions, that the retention failed to delete, back into .Kept, keeping the newest-first order
+func (p *RetentionPlan) keepNotDeleted(versions []Version) {
+	for _, version := range versions {
+		p.Kept = append(p.Kept, RetainedVersion{Version: version, Reasons: []string{"notDeleted"}})
+	}
+	sort.SliceStable(p.Kept, func(i, j int) bool {
+		if p.Kept[i].VersionNumber != p.Kept[j].VersionNumber {
+			return p.Kept[i].VersionNumber > p.Kept[j].VersionNumber
+		}
+		return p.Kept[i].CreatedAt.After(p.Kept[j].CreatedAt)
+	})
+}
+
+// ToRetentionStatus is reporting the plan in .status.retention of the ScheduledBackup
+func (p RetentionPlan) ToRetentionStatus(dryRun bool, now time.Time) *v1alpha1.RetentionStatus {
+	appliedTime := metav1.NewTime(now)
+	status := &v1alpha1.RetentionStatus{LastAppliedTime: &appliedTime, DryRun: dryRun}
+	for _, kept := range p.Kept {
+		status.Kept = append(status.Kept, kept.GetName())
+	}
+	for _, removed := range p.Removed {
+		status.Removed = append(status.Removed, removed.GetName())
+	}
+	return status
+}
+
+// GetName is a version reference understood by Backup Maker's `restore --version`, e.g. "v3"
+func (v Version) GetName() string {
+	return fmt.Sprintf("v%d", v.VersionNumber)
+}
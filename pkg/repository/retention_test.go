@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createDailyVersions is a collection of daily backups from 2023-01-01 (v1) to 2023-03-01 (v60), starting from the newest one
+func createDailyVersions() []Version {
+	var versions []Version
+	for number := 60; number >= 1; number-- {
+		versions = append(versions, Version{
+			Id:            "id-" + time.Date(2023, 1, number, 2, 0, 0, 0, time.UTC).Format("0102"),
+			VersionNumber: number,
+			CreatedAt:     time.Date(2023, 1, number, 2, 0, 0, 0, time.UTC),
+		})
+	}
+	return versions
+}
+
+func keptNames(plan RetentionPlan) []string {
+	names := make([]string, 0, len(plan.Kept))
+	for _, kept := range plan.Kept {
+		names = append(names, kept.GetName())
+	}
+	return names
+}
+
+var retentionNow = time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestPlanRetention_KeepLast(t *testing.T) {
+	plan := PlanRetention(createDailyVersions(), v1alpha1.RetentionSpec{KeepLast: 3}, nil, retentionNow)
+
+	assert.Equal(t, []string{"v60", "v59", "v58"}, keptNames(plan))
+	assert.Len(t, plan.Removed, 57)
+	assert.Equal(t, "v57", plan.Removed[0].GetName())
+	assert.Equal(t, []string{"newest", "last"}, plan.Kept[0].Reasons)
+}
+
+func TestPlanRetention_KeepNewestPerPeriod(t *testing.T) {
+	plan := PlanRetention(createDailyVersions(), v1alpha1.RetentionSpec{KeepDaily: 7, KeepWeekly: 4, KeepMonthly: 3}, nil, retentionNow)
+
+	// weeks start on Monday: 2023-02-26 (v57), 2023-02-19 (v50) and 2023-02-12 (v43) are Sundays. Months end on v59 and v31
+	assert.Equal(t, []string{"v60", "v59", "v58", "v57", "v56", "v55", "v54", "v50", "v43", "v31"}, keptNames(plan))
+	assert.Equal(t, []string{"newest", "daily", "weekly", "monthly"}, plan.Kept[0].Reasons)
+	assert.Equal(t, []string{"daily", "monthly"}, plan.Kept[1].Reasons)
+	assert.Equal(t, []string{"monthly"}, plan.Kept[9].Reasons)
+	assert.Len(t, plan.Removed, 50)
+}
+
+func TestPlanRetention_MaxAge(t *testing.T) {
+	onlyMaxAge := PlanRetention(createDailyVersions(), v1alpha1.RetentionSpec{MaxAge: &metav1.Duration{Duration: time.Hour * 72}}, nil, retentionNow)
+	assert.Equal(t, []string{"v60", "v59", "v58"}, keptNames(onlyMaxAge))
+	assert.Equal(t, []string{"maxAge"}, onlyMaxAge.Kept[1].Reasons)
+
+	// versions selected by keep* rules are deleted as well, when expired
+	withKeepRules := PlanRetention(createDailyVersions(), v1alpha1.RetentionSpec{KeepMonthly: 3, MaxAge: &metav1.Duration{Duration: time.Hour * 240}}, nil, retentionNow)
+	assert.Equal(t, []string{"v60", "v59"}, keptNames(withKeepRules))
+}
+
+func TestPlanRetention_NewestVersionIsAlwaysKept(t *testing.T) {
+	plan := PlanRetention(createDailyVersions(), v1alpha1.RetentionSpec{MaxAge: &metav1.Duration{Duration: time.Hour}}, nil, retentionNow.AddDate(0, 1, 0))
+
+	assert.Equal(t, []string{"v60"}, keptNames(plan))
+	assert.Len(t, plan.Removed, 59)
+
+	empty := PlanRetention(nil, v1alpha1.RetentionSpec{KeepLast: 3}, nil, retentionNow)
+	assert.Empty(t, empty.Kept)
+	assert.Empty(t, empty.Removed)
+}
+
+func TestPlanRetention_PinnedVersionsAreKept(t *testing.T) {
+	// v2 is selected by a restore that is not finished yet, the restore would fail if v2 was deleted before it starts
+	plan := PlanRetention(createDailyVersions(), v1alpha1.RetentionSpec{KeepLast: 1, MaxAge: &metav1.Duration{Duration: time.Hour * 24}}, []string{"v2"}, retentionNow)
+
+	assert.Equal(t, []string{"v60", "v2"}, keptNames(plan))
+	assert.Equal(t, []string{"restoring"}, plan.Kept[1].Reasons)
+	assert.Len(t, plan.Removed, 58)
+}
+
+// serveCollection is a stand-in of the Backup Repository server, that allows to list and delete versions
+func serveCollection(t *testing.T, versions []Version, failOn string) (*httptest.Server, *[]string) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer jwt", r.Header.Get("Authorization"))
+		if r.Method == http.MethodGet {
+			assert.Equal(t, "/api/alpha/repository/collection/iwa-ait/version", r.URL.Path)
+			response := versionsResponse{Status: true}
+			response.Data.Versions = versions
+			_ = json.NewEncoder(w).Encode(response)
+			return
+		}
+		assert.Equal(t, http.MethodDelete, r.Method)
+		versionId := strings.TrimPrefix(r.URL.Path, "/api/alpha/repository/collection/iwa-ait/version/")
+		if versionId == failOn {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"status": false, "message": "storage is not available"}`))
+			return
+		}
+		deleted = append(deleted, versionId)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return server, &deleted
+}
+
+func TestApplyRetention(t *testing.T) {
+	server, deleted := serveCollection(t, createVersions(), "")
+	defer server.Close()
+
+	plan, err := ApplyRetention(context.TODO(), NewClient(server.URL, "jwt"), "iwa-ait", v1alpha1.RetentionSpec{KeepLast: 1}, nil, retentionNow)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bbb", "aaa"}, *deleted)
+	assert.Equal(t, []string{"v3"}, keptNames(plan))
+
+	status := plan.ToRetentionStatus(false, retentionNow)
+	assert.Equal(t, []string{"v3"}, status.Kept)
+	assert.Equal(t, []string{"v2", "v1"}, status.Removed)
+	assert.Equal(t, retentionNow, status.GetLastAppliedTime())
+}
+
+func TestApplyRetention_DryRunDoesNotDelete(t *testing.T) {
+	server, deleted := serveCollection(t, createVersions(), "")
+	defer server.Close()
+
+	plan, err := ApplyRetention(context.TODO(), NewClient(server.URL, "jwt"), "iwa-ait", v1alpha1.RetentionSpec{KeepLast: 1, DryRun: true}, nil, retentionNow)
+
+	assert.Nil(t, err)
+	assert.Empty(t, *deleted)
+	assert.Len(t, plan.Removed, 2)
+}
+
+func TestApplyRetention_ReportsVersionsLeftOnFailure(t *testing.T) {
+	server, deleted := serveCollection(t, createVersions(), "aaa")
+	defer server.Close()
+
+	plan, err := ApplyRetention(context.TODO(), NewClient(server.URL, "jwt"), "iwa-ait", v1alpha1.RetentionSpec{KeepLast: 1}, nil, retentionNow)
+
+	assert.ErrorContains(t, err, "deleted 1 of 2 versions: cannot delete version 'aaa' of collection 'iwa-ait'")
+	assert.ErrorContains(t, err, "500 Internal Server Error: storage is not available")
+	assert.Equal(t, []string{"bbb"}, *deleted)
+	assert.Equal(t, "v2", plan.Removed[0].GetName())
+	assert.Len(t, plan.Removed, 1)
+
+	// the version that could not be deleted is still in the collection
+	assert.Equal(t, []string{"v3", "v1"}, keptNames(plan))
+	assert.Equal(t, []string{"notDeleted"}, plan.Kept[1].Reasons)
+}
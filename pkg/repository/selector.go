@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoMatchingVersion means, that the collection does not contain a version selected by .spec.targetVersion
+var ErrNoMatchingVersion = errors.New("no matching version")
+
+const (
+	selectorLatest       = "latest"
+	selectorLatestBefore = "latest-before:"
+	selectorPrevious     = "previous:"
+)
+
+// Selector is a parsed .spec.targetVersion of a restore. Accepted formats:
+//
+//	latest                                 the newest version, also when .spec.targetVersion is empty
+//	latest-before: 2023-01-02T00:00:00Z    the newest version uploaded before given time (RFC3339)
+//	previous:N                             N-th version before the newest one, "previous:1" is the second newest
+//	v3, 3                                  exact version number
+//	<id>                                   exact version id
+type Selector struct {
+	raw      string
+	before   *time.Time
+	previous int
+	number   int
+}
+
+// ParseSelector is validating the syntax of .spec.targetVersion, without checking if the version exists
+func ParseSelector(targetVersion string) (Selector, error) {
+	raw := strings.TrimSpace(targetVersion)
+	selector := Selector{raw: raw}
+
+	switch {
+	case raw == "" || raw == selectorLatest:
+		selector.raw = selectorLatest
+
+	case strings.HasPrefix(raw, selectorLatestBefore):
+		value := strings.TrimSpace(strings.TrimPrefix(raw, selectorLatestBefore))
+		before, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return Selector{}, errors.Errorf("'%s' is not a valid RFC3339 time, expected e.g. 'latest-before: 2023-01-02T00:00:00Z'", value)
+		}
+		selector.before = &before
+
+	case strings.HasPrefix(raw, selectorPrevious):
+		value := strings.TrimSpace(strings.TrimPrefix(raw, selectorPrevious))
+		previous, err := strconv.Atoi(value)
+		if err != nil || previous < 1 {
+			return Selector{}, errors.Errorf("'%s' is not a positive number, expected e.g. 'previous:1'", value)
+		}
+		selector.previous = previous
+
+	default:
+		if number, err := strconv.Atoi(strings.TrimPrefix(raw, "v")); err == nil && number > 0 {
+			selector.number = number
+		}
+	}
+	return selector, nil
+}
+
+// IsSymbolic tells if the version can be known only by looking into the collection, e.g. "previous:2"
+func (s Selector) IsSymbolic() bool {
+	return s.before != nil || s.previous > 0
+}
+
+func (s Selector) String() string {
+	return s.raw
+}
+
+// Resolve is picking the selected version from versions sorted with SortNewestFirst
+func (s Selector) Resolve(versions []Version) (Version, error) {
+	switch {
+	case s.before != nil:
+		for _, version := range versions {
+			if version.CreatedAt.Before(*s.before) {
+				return version, nil
+			}
+		}
+		return Version{}, errors.Wrapf(ErrNoMatchingVersion, "none of %d versions was uploaded before %s", len(versions), s.before.Format(time.RFC3339))
+
+	case s.previous > 0:
+		if s.previous < len(versions) {
+			return versions[s.previous], nil
+		}
+		return Version{}, errors.Wrapf(ErrNoMatchingVersion, "'%s' needs at least %d versions, collection contains %d", s.raw, s.previous+1, len(versions))
+
+	case s.raw == selectorLatest:
+		if len(versions) > 0 {
+			return versions[0], nil
+		}
+		return Version{}, errors.Wrap(ErrNoMatchingVersion, "collection does not contain any version")
+	}
+
+	for _, version := range versions {
+		if version.Id == s.raw || (s.number > 0 && version.VersionNumber == s.number) {
+			return version, nil
+		}
+	}
+	return Version{}, errors.Wrapf(ErrNoMatchingVersion, "version '%s' not found in %d versions", s.raw, len(versions))
+}
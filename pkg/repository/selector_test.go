@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func createVersions() []Version {
+	day := func(d int) time.Time { return time.Date(2023, 1, d, 2, 0, 0, 0, time.UTC) }
+	return []Version{
+		{Id: "ccc", VersionNumber: 3, CreatedAt: day(3)},
+		{Id: "bbb", VersionNumber: 2, CreatedAt: day(2)},
+		{Id: "aaa", VersionNumber: 1, CreatedAt: day(1)},
+	}
+}
+
+func TestSelector_Resolve(t *testing.T) {
+	for targetVersion, expectedId := range map[string]string{
+		"":                                    "ccc",
+		"latest":                              "ccc",
+		"latest-before: 2023-01-03T00:00:00Z": "bbb",
+		"latest-before:2023-01-02T02:00:01Z":  "bbb",
+		"latest-before: 2023-01-02T03:00:00+02:00": "aaa",
+		"previous:1":  "bbb",
+		"previous: 2": "aaa",
+		"v1":          "aaa",
+		"2":           "bbb",
+		"ccc":         "ccc",
+	} {
+		selector, err := ParseSelector(targetVersion)
+		assert.Nil(t, err, targetVersion)
+
+		version, err := selector.Resolve(createVersions())
+		assert.Nil(t, err, targetVersion)
+		assert.Equal(t, expectedId, version.Id, targetVersion)
+	}
+}
+
+func TestSelector_Resolve_NoMatch(t *testing.T) {
+	for _, targetVersion := range []string{"latest-before: 2023-01-01T02:00:00Z", "previous:3", "v4", "ddd"} {
+		selector, err := ParseSelector(targetVersion)
+		assert.Nil(t, err, targetVersion)
+
+		_, err = selector.Resolve(createVersions())
+		assert.ErrorIs(t, err, ErrNoMatchingVersion, targetVersion)
+	}
+
+	latest, _ := ParseSelector("latest")
+	_, err := latest.Resolve([]Version{})
+	assert.ErrorContains(t, err, "collection does not contain any version")
+}
+
+func TestParseSelector_Invalid(t *testing.T) {
+	_, err := ParseSelector("latest-before: yesterday")
+	assert.ErrorContains(t, err, "'yesterday' is not a valid RFC3339 time")
+
+	for _, targetVersion := range []string{"previous:0", "previous:-1", "previous:one"} {
+		_, err = ParseSelector(targetVersion)
+		assert.ErrorContains(t, err, "is not a positive number", targetVersion)
+	}
+}
+
+func TestSelector_IsSymbolic(t *testing.T) {
+	for targetVersion, symbolic := range map[string]bool{"": false, "latest": false, "v3": false, "previous:1": true, "latest-before: 2023-01-01T00:00:00Z": true} {
+		selector, err := ParseSelector(targetVersion)
+		assert.Nil(t, err)
+		assert.Equal(t, symbolic, selector.IsSymbolic(), targetVersion)
+	}
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+// NewRepositoryStatus is publishing the newest versions (sorted with SortNewestFirst) in the ScheduledBackup's status
+func NewRepositoryStatus(versions []Version, limit int, now time.Time) *v1alpha1.RepositoryStatus {
+	syncTime := metav1.NewTime(now)
+	status := &v1alpha1.RepositoryStatus{LastSyncTime: &syncTime, TotalVersions: len(versions)}
+	for _, version := range versions {
+		if len(status.Versions) >= limit {
+			break
+		}
+		status.Versions = append(status.Versions, version.ToBackupVersion())
+	}
+	return status
+}
+
+// ToBackupVersion is converting the version into a form stored in the status of ScheduledBackup and RequestedBackupAction
+func (v Version) ToBackupVersion() v1alpha1.BackupVersion {
+	return v1alpha1.BackupVersion{
+		Id:        v.Id,
+		Number:    v.VersionNumber,
+		Filename:  v.Filename,
+		Size:      v.Filesize,
+		CreatedAt: metav1.NewTime(v.CreatedAt),
+	}
+}
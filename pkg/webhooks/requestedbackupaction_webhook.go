@@ -0,0 +1,231 @@
+/*
+Copyright 2022 Riotkit.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/bmg"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/riotkit-org/backup-maker-controller/pkg/repository"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"strings"
+)
+
+//+kubebuilder:webhook:path=/mutate-riotkit-org-v1alpha1-requestedbackupaction,mutating=true,failurePolicy=fail,sideEffects=None,groups=riotkit.org,resources=requestedbackupactions,verbs=create;update,versions=v1alpha1,name=mrequestedbackupaction.riotkit.org,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-riotkit-org-v1alpha1-requestedbackupaction,mutating=false,failurePolicy=fail,sideEffects=None,groups=riotkit.org,resources=requestedbackupactions,verbs=create;update,versions=v1alpha1,name=vrequestedbackupaction.riotkit.org,admissionReviewVersions=v1
+
+// RequestedBackupActionDefaulter persists default values, so the stored object shows the effective configuration
+type RequestedBackupActionDefaulter struct{}
+
+func (d *RequestedBackupActionDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	action, ok := obj.(*v1alpha1.RequestedBackupAction)
+	if !ok {
+		return fmt.Errorf("expected a RequestedBackupAction, got %T", obj)
+	}
+	action.Spec.Default()
+	return nil
+}
+
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// RequestedBackupActionValidator rejects RequestedBackupActions that are pointing to not existing ScheduledBackup or are inconsistent
+type RequestedBackupActionValidator struct {
+	Client client.Reader
+
+	// Authorizer is checking if the requesting user is allowed to restore into .spec.restoreTarget.namespace
+	Authorizer authorizationv1client.SubjectAccessReviewInterface
+
+	// RunnableKinds are kinds of integrations enabled in the controller, that can be used in .spec.kindType
+	RunnableKinds []schema.GroupVersionKind
+}
+
+// restoreTargetPermissions are required in the target namespace - the controller creates objects there on behalf of the user,
+// including a Secret with the GPG private key
+var restoreTargetPermissions = []authorizationv1.ResourceAttributes{
+	{Group: v1alpha1.SchemeGroupVersion.Group, Resource: "requestedbackupactions", Verb: "create"},
+	{Group: "", Resource: "secrets", Verb: "create"},
+}
+
+func (v *RequestedBackupActionValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	action, ok := obj.(*v1alpha1.RequestedBackupAction)
+	if !ok {
+		return fmt.Errorf("expected a RequestedBackupAction, got %T", obj)
+	}
+
+	errs, err := v.validate(ctx, action)
+	if err != nil {
+		return err
+	}
+	return toInvalidError("RequestedBackupAction", action.Name, errs)
+}
+
+// ValidateUpdate is validating only a changed spec. Metadata updates, like removing a finalizer of an action
+// which ScheduledBackup is already deleted, should not be blocked by the references that no longer exist
+func (v *RequestedBackupActionValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldAction, ok := oldObj.(*v1alpha1.RequestedBackupAction)
+	if !ok {
+		return fmt.Errorf("expected a RequestedBackupAction, got %T", oldObj)
+	}
+	action, ok := newObj.(*v1alpha1.RequestedBackupAction)
+	if !ok {
+		return fmt.Errorf("expected a RequestedBackupAction, got %T", newObj)
+	}
+	if action.DeletionTimestamp != nil || equality.Semantic.DeepEqual(oldAction.Spec, action.Spec) {
+		return nil
+	}
+	return v.ValidateCreate(ctx, newObj)
+}
+
+func (v *RequestedBackupActionValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func (v *RequestedBackupActionValidator) validate(ctx context.Context, action *v1alpha1.RequestedBackupAction) (field.ErrorList, error) {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	// .spec.targetVersion: only a restore can select a version
+	if action.Spec.TargetVersion != "" && action.Spec.Action == string(domain.Backup) {
+		errs = append(errs, field.Forbidden(specPath.Child("targetVersion"), "can be set only when .spec.action is 'restore'"))
+	}
+	// the version itself is checked against the Backup Repository by the controller, before the restore starts
+	if _, parseErr := repository.ParseSelector(action.Spec.TargetVersion); parseErr != nil {
+		errs = append(errs, field.Invalid(specPath.Child("targetVersion"), action.Spec.TargetVersion, parseErr.Error()))
+	}
+
+	// .spec.kindType: the CRD enumerates all integrations, but only enabled ones can run the action
+	kindType := action.Spec.KindType
+	if kindType == "" {
+		kindType = v1alpha1.DefaultKindType
+	}
+	if !domain.IsRunnableKindType(v.RunnableKinds, kindType) {
+		errs = append(errs, field.NotSupported(specPath.Child("kindType"), kindType, domain.RunnableKindTypeNames(v.RunnableKinds)))
+	}
+
+	// .spec.retryPolicy: only a finished, not successful attempt can be retried
+	if policy := action.Spec.RetryPolicy; policy != nil {
+		policyPath := specPath.Child("retryPolicy")
+		if policy.MaxAttempts < 1 {
+			errs = append(errs, field.Invalid(policyPath.Child("maxAttempts"), policy.MaxAttempts, "must be at least 1"))
+		}
+		if policy.Backoff.Duration < 0 {
+			errs = append(errs, field.Invalid(policyPath.Child("backoff"), policy.Backoff.Duration.String(), "cannot be negative"))
+		}
+		for i, phase := range policy.OnlyOn {
+			if phase != v1alpha1.PhaseFailed && phase != v1alpha1.PhaseTimedOut {
+				errs = append(errs, field.NotSupported(policyPath.Child("onlyOn").Index(i), phase,
+					[]string{string(v1alpha1.PhaseFailed), string(v1alpha1.PhaseTimedOut)}))
+			}
+		}
+	}
+
+	// .spec.timeout
+	errs = append(errs, validateDuration(specPath.Child("timeout"), action.Spec.Timeout)...)
+
+	// .spec.restoreTarget
+	if action.Spec.RestoreTarget != nil {
+		targetErrs, err := v.validateRestoreTarget(ctx, specPath.Child("restoreTarget"), action)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, targetErrs...)
+	}
+
+	// .spec.scheduledBackupRef: must exist in the same namespace
+	refPath := specPath.Child("scheduledBackupRef", "name")
+	if action.Spec.ScheduledBackupRef.Name == "" {
+		return append(errs, field.Required(refPath, "ScheduledBackup name is required")), nil
+	}
+	backup := v1alpha1.ScheduledBackup{}
+	getErr := v.Client.Get(ctx, client.ObjectKey{Name: action.Spec.ScheduledBackupRef.Name, Namespace: action.Namespace}, &backup)
+	if apierrors.IsNotFound(getErr) {
+		errs = append(errs, field.NotFound(refPath, action.Spec.ScheduledBackupRef.Name))
+	} else if getErr != nil {
+		return nil, errors.Wrapf(getErr, "cannot check if ScheduledBackup '%s' exists", action.Spec.ScheduledBackupRef.Name)
+	}
+	return errs, nil
+}
+
+// validateRestoreTarget is checking the target and if the requesting user could perform the restore in the target namespace by itself
+func (v *RequestedBackupActionValidator) validateRestoreTarget(ctx context.Context, path *field.Path, action *v1alpha1.RequestedBackupAction) (field.ErrorList, error) {
+	var errs field.ErrorList
+	target := action.Spec.RestoreTarget
+	if action.Spec.Action != string(domain.Restore) {
+		errs = append(errs, field.Forbidden(path, "can be set only when .spec.action is 'restore'"))
+	}
+	if _, err := bmg.ParseVars(string(target.Vars)); err != nil {
+		errs = append(errs, field.Invalid(path.Child("vars"), omittedValue, err.Error()))
+	}
+	if target.Namespace == "" || target.Namespace == action.Namespace {
+		return errs, nil
+	}
+	if msgs := validation.IsDNS1123Label(target.Namespace); len(msgs) > 0 {
+		return append(errs, field.Invalid(path.Child("namespace"), target.Namespace, strings.Join(msgs, ", "))), nil
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot check permissions in the target namespace")
+	}
+	for _, permission := range restoreTargetPermissions {
+		permission.Namespace = target.Namespace
+		allowed, reviewErr := v.isAllowed(ctx, req.UserInfo, permission)
+		if reviewErr != nil {
+			return nil, reviewErr
+		}
+		if !allowed {
+			errs = append(errs, field.Forbidden(path.Child("namespace"), fmt.Sprintf("user '%s' cannot %s %s in namespace '%s'",
+				req.UserInfo.Username, permission.Verb, permission.Resource, target.Namespace)))
+		}
+	}
+	return errs, nil
+}
+
+// isAllowed is asking the API server, if the user has given permission
+func (v *RequestedBackupActionValidator) isAllowed(ctx context.Context, user authenticationv1.UserInfo, permission authorizationv1.ResourceAttributes) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review, err := v.Authorizer.Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &permission,
+			User:               user.Username,
+			Groups:             user.Groups,
+			UID:                user.UID,
+			Extra:              extra,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot check if user '%s' can %s %s in namespace '%s'", user.Username, permission.Verb, permission.Resource, permission.Namespace)
+	}
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 Riotkit.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/riotkit-org/backup-maker-controller/pkg/bmg"
+	"github.com/riotkit-org/backup-maker-controller/pkg/domain"
+	"github.com/robfig/cron/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"strings"
+	"time"
+)
+
+//+kubebuilder:webhook:path=/mutate-riotkit-org-v1alpha1-scheduledbackup,mutating=true,failurePolicy=fail,sideEffects=None,groups=riotkit.org,resources=scheduledbackups,verbs=create;update,versions=v1alpha1,name=mscheduledbackup.riotkit.org,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-riotkit-org-v1alpha1-scheduledbackup,mutating=false,failurePolicy=fail,sideEffects=None,groups=riotkit.org,resources=scheduledbackups,verbs=create;update,versions=v1alpha1,name=vscheduledbackup.riotkit.org,admissionReviewVersions=v1
+
+// omittedValue is reported instead of the invalid value, when the value could contain credentials
+const omittedValue = "(omitted)"
+
+// ScheduledBackupDefaulter persists default values, so the stored object shows the effective configuration
+type ScheduledBackupDefaulter struct{}
+
+func (d *ScheduledBackupDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	backup, ok := obj.(*v1alpha1.ScheduledBackup)
+	if !ok {
+		return fmt.Errorf("expected a ScheduledBackup, got %T", obj)
+	}
+	backup.Spec.Default()
+	return nil
+}
+
+// ScheduledBackupValidator rejects ScheduledBackups that would fail on rendering
+type ScheduledBackupValidator struct{}
+
+func (v *ScheduledBackupValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	backup, ok := obj.(*v1alpha1.ScheduledBackup)
+	if !ok {
+		return fmt.Errorf("expected a ScheduledBackup, got %T", obj)
+	}
+	return toInvalidError("ScheduledBackup", backup.Name, validateScheduledBackupSpec(backup.Name, &backup.Spec))
+}
+
+func (v *ScheduledBackupValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return v.ValidateCreate(ctx, newObj)
+}
+
+func (v *ScheduledBackupValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validateScheduledBackupSpec is checking everything that can be checked without fetching referenced objects
+func validateScheduledBackupSpec(name string, spec *v1alpha1.ScheduledBackupSpec) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	// .spec.templateRef
+	templateRefPath := specPath.Child("templateRef")
+	if spec.TemplateRef.Name == "" {
+		errs = append(errs, field.Required(templateRefPath.Child("name"), "template name is required"))
+	}
+	if !domain.IsKnownTemplateKind(spec.TemplateRef.Kind) {
+		errs = append(errs, field.NotSupported(templateRefPath.Child("kind"), spec.TemplateRef.Kind, []string{
+			domain.InternalTemplateKind, domain.ClusterBackupProcedureTemplateKind, domain.BackupProcedureTemplateKind,
+		}))
+	}
+
+	// .spec.vars: parsed the same way as on rendering
+	if _, err := bmg.ParseVars(string(spec.Vars)); err != nil {
+		errs = append(errs, field.Invalid(specPath.Child("vars"), omittedValue, err.Error()))
+	}
+
+	// .spec.cronJob.scheduleEvery: Kubernetes CronJob is using the standard 5-field format
+	if spec.CronJob.Enabled {
+		errs = append(errs, validateCronSchedule(specPath.Child("cronJob", "scheduleEvery"), spec.CronJob.ScheduleEvery)...)
+	}
+
+	// .spec.schedules
+	errs = append(errs, validateSchedules(specPath.Child("schedules"), name, spec.Schedules)...)
+
+	// .spec.timeout, .spec.maxBackupAge
+	errs = append(errs, validateDuration(specPath.Child("timeout"), spec.Timeout)...)
+	errs = append(errs, validateDuration(specPath.Child("maxBackupAge"), spec.MaxBackupAge)...)
+
+	// .spec.retention: an empty policy would keep everything, which is rather a mistake
+	if retention := spec.Retention; retention != nil {
+		retentionPath := specPath.Child("retention")
+		if !retention.HasKeepRules() && retention.MaxAge == nil {
+			errs = append(errs, field.Required(retentionPath, "at least one of keepLast, keepDaily, keepWeekly, keepMonthly or maxAge is required"))
+		}
+		errs = append(errs, validateDuration(retentionPath.Child("maxAge"), retention.MaxAge)...)
+	}
+	return errs
+}
+
+// validateSchedules is checking, that each schedule could be turned into a separate CronJob
+func validateSchedules(path *field.Path, name string, schedules []v1alpha1.ScheduleSpec) field.ErrorList {
+	var errs field.ErrorList
+	names := make(map[string]bool)
+	for i, schedule := range schedules {
+		schedulePath := path.Index(i)
+		if schedule.Name == "" {
+			errs = append(errs, field.Required(schedulePath.Child("name"), "schedule name is required"))
+		} else if msgs := validation.IsDNS1123Label(schedule.Name); len(msgs) > 0 {
+			errs = append(errs, field.Invalid(schedulePath.Child("name"), schedule.Name, strings.Join(msgs, ", ")))
+		} else if names[schedule.Name] {
+			errs = append(errs, field.Duplicate(schedulePath.Child("name"), schedule.Name))
+		} else if cronJobName := schedule.GetCronJobName(name); len(cronJobName) > v1alpha1.MaxCronJobNameLength {
+			errs = append(errs, field.TooLong(schedulePath.Child("name"), cronJobName, v1alpha1.MaxCronJobNameLength))
+		}
+		names[schedule.Name] = true
+
+		if op := domain.Operation(schedule.Operation); op != domain.Backup && op != domain.Restore {
+			errs = append(errs, field.NotSupported(schedulePath.Child("operation"), schedule.Operation, []string{string(domain.Backup), string(domain.Restore)}))
+		}
+		errs = append(errs, validateCronSchedule(schedulePath.Child("scheduleEvery"), schedule.ScheduleEvery)...)
+	}
+	return errs
+}
+
+// validateCronSchedule is checking the schedule the same way as Kubernetes validates .spec.schedule of a CronJob.
+// The cron library accepts also "@every <duration>" and a time zone prefix, which are rejected by the API server
+func validateCronSchedule(path *field.Path, schedule string) field.ErrorList {
+	if strings.HasPrefix(schedule, "@every") {
+		return field.ErrorList{field.Invalid(path, schedule, "'@every' is not supported by Kubernetes CronJob, use the standard 5-field format")}
+	}
+	if strings.Contains(schedule, "TZ") {
+		return field.ErrorList{field.Invalid(path, schedule, "'TZ' and 'CRON_TZ' are not supported by Kubernetes CronJob, the schedule is in the time zone of kube-controller-manager")}
+	}
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return field.ErrorList{field.Invalid(path, schedule, err.Error())}
+	}
+	return nil
+}
+
+// validateDuration is checking, that the duration is at least one second - e.g. a timeout is converted into seconds of `activeDeadlineSeconds`
+func validateDuration(path *field.Path, duration *metav1.Duration) field.ErrorList {
+	if duration != nil && duration.Duration < time.Second {
+		return field.ErrorList{field.Invalid(path, duration.Duration.String(), "must be at least 1s")}
+	}
+	return nil
+}
+
+// toInvalidError is converting a list of field errors into an API error, which is readable by kubectl
+func toInvalidError(kind string, name string, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(v1alpha1.SchemeGroupVersion.WithKind(kind).GroupKind(), name, errs)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2022 Riotkit.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"github.com/pkg/errors"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// SetupWithManager is registering defaulting and validating admission webhooks served by the manager.
+// runnableKinds are kinds of enabled integrations, that are accepted in .spec.kindType of RequestedBackupAction
+func SetupWithManager(mgr ctrl.Manager, runnableKinds []schema.GroupVersionKind) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.ScheduledBackup{}).
+		WithDefaulter(&ScheduledBackupDefaulter{}).
+		WithValidator(&ScheduledBackupValidator{}).
+		Complete(); err != nil {
+		return err
+	}
+	authorizationClient, err := authorizationv1client.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return errors.Wrap(err, "cannot create authorization/v1 client")
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.RequestedBackupAction{}).
+		WithDefaulter(&RequestedBackupActionDefaulter{}).
+		WithValidator(&RequestedBackupActionValidator{Client: mgr.GetAPIReader(), Authorizer: authorizationClient.SubjectAccessReviews(), RunnableKinds: runnableKinds}).
+		Complete()
+}
@@ -0,0 +1,310 @@
+package webhooks
+
+import (
+	"context"
+	"github.com/riotkit-org/backup-maker-controller/pkg/apis/riotkit/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"testing"
+	"time"
+)
+
+// testRunnableKinds are kinds of a controller, that has only Kubernetes integrations enabled
+var testRunnableKinds = []schema.GroupVersionKind{
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "", Version: "v1", Kind: "Pod"},
+}
+
+func createValidScheduledBackup() *v1alpha1.ScheduledBackup {
+	return &v1alpha1.ScheduledBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "default"},
+		Spec: v1alpha1.ScheduledBackupSpec{
+			Operation:   "backup",
+			TemplateRef: v1alpha1.TemplateSpec{Kind: "internal", Name: "pg14"},
+			Vars:        "Params:\n    hostname: postgres\n",
+			CronJob:     v1alpha1.CronJobSpec{Enabled: true, ScheduleEvery: "00 02 * * *"},
+		},
+	}
+}
+
+func TestScheduledBackupValidator_AcceptsValid(t *testing.T) {
+	assert.Nil(t, (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), createValidScheduledBackup()))
+}
+
+func TestScheduledBackupValidator_ReportsFieldPaths(t *testing.T) {
+	backup := createValidScheduledBackup()
+	backup.Spec.TemplateRef = v1alpha1.TemplateSpec{Kind: "Helm", Name: ""}
+	backup.Spec.Vars = "Params: [unclosed"
+	backup.Spec.CronJob.ScheduleEvery = "every monday"
+	backup.Spec.Timeout = &metav1.Duration{Duration: time.Millisecond * 10}
+
+	err := (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), backup)
+
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "spec.templateRef.name: Required value")
+	assert.Contains(t, err.Error(), "spec.templateRef.kind: Unsupported value: \"Helm\"")
+	assert.Contains(t, err.Error(), "spec.vars: Invalid value: \"(omitted)\": cannot parse .spec.vars as YAML")
+	assert.Contains(t, err.Error(), "spec.cronJob.scheduleEvery: Invalid value: \"every monday\"")
+	assert.Contains(t, err.Error(), "spec.timeout: Invalid value: \"10ms\": must be at least 1s")
+}
+
+func TestScheduledBackupValidator_ScheduleIsIgnoredWhenCronJobDisabled(t *testing.T) {
+	backup := createValidScheduledBackup()
+	backup.Spec.CronJob = v1alpha1.CronJobSpec{Enabled: false, ScheduleEvery: "every monday"}
+
+	assert.Nil(t, (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), backup))
+}
+
+func TestScheduledBackupValidator_Schedules(t *testing.T) {
+	backup := createValidScheduledBackup()
+	backup.Spec.Schedules = []v1alpha1.ScheduleSpec{
+		{Name: "verify", Operation: "restore", ScheduleEvery: "00 04 * * 0"},
+	}
+	assert.Nil(t, (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), backup))
+
+	backup.Spec.Schedules = append(backup.Spec.Schedules,
+		v1alpha1.ScheduleSpec{Name: "verify", Operation: "restore", ScheduleEvery: "00 05 * * 0"},
+		v1alpha1.ScheduleSpec{Name: "", Operation: "download", ScheduleEvery: "sometimes"},
+		v1alpha1.ScheduleSpec{Name: "a-very-long-name-of-the-schedule-that-exceeds-limits", Operation: "backup", ScheduleEvery: "@daily"},
+	)
+	err := (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), backup)
+
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "spec.schedules[1].name: Duplicate value: \"verify\"")
+	assert.Contains(t, err.Error(), "spec.schedules[2].name: Required value")
+	assert.Contains(t, err.Error(), "spec.schedules[2].operation: Unsupported value: \"download\"")
+	assert.Contains(t, err.Error(), "spec.schedules[2].scheduleEvery: Invalid value: \"sometimes\"")
+	assert.Contains(t, err.Error(), "spec.schedules[3].name: Too long")
+}
+
+// TestScheduledBackupValidator_KubernetesCronFormat is checking schedules, that the cron library accepts, but Kubernetes CronJob does not
+func TestScheduledBackupValidator_KubernetesCronFormat(t *testing.T) {
+	for _, schedule := range []string{"@every 5m", "TZ=Europe/Warsaw 00 02 * * *", "CRON_TZ=UTC 00 02 * * *"} {
+		backup := createValidScheduledBackup()
+		backup.Spec.CronJob.ScheduleEvery = schedule
+		backup.Spec.Schedules = []v1alpha1.ScheduleSpec{{Name: "verify", Operation: "restore", ScheduleEvery: schedule}}
+
+		err := (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), backup)
+
+		assert.True(t, apierrors.IsInvalid(err), "Expected that '%s' is rejected", schedule)
+		assert.Contains(t, err.Error(), "spec.cronJob.scheduleEvery: Invalid value: \""+schedule+"\": ")
+		assert.Contains(t, err.Error(), "spec.schedules[0].scheduleEvery: Invalid value: \""+schedule+"\": ")
+	}
+
+	backup := createValidScheduledBackup()
+	backup.Spec.CronJob.ScheduleEvery = "@weekly"
+	assert.Nil(t, (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), backup))
+}
+
+func TestScheduledBackupValidator_Retention(t *testing.T) {
+	backup := createValidScheduledBackup()
+	backup.Spec.Retention = &v1alpha1.RetentionSpec{KeepDaily: 7, MaxAge: &metav1.Duration{Duration: time.Hour * 24 * 90}}
+	assert.Nil(t, (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), backup))
+
+	backup.Spec.Retention = &v1alpha1.RetentionSpec{DryRun: true}
+	err := (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), backup)
+	assert.Contains(t, err.Error(), "spec.retention: Required value: at least one of keepLast, keepDaily, keepWeekly, keepMonthly or maxAge is required")
+
+	backup.Spec.Retention = &v1alpha1.RetentionSpec{MaxAge: &metav1.Duration{Duration: time.Millisecond}}
+	err = (&ScheduledBackupValidator{}).ValidateCreate(context.TODO(), backup)
+	assert.Contains(t, err.Error(), "spec.retention.maxAge: Invalid value: \"1ms\": must be at least 1s")
+}
+
+func TestRequestedBackupActionValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	validator := RequestedBackupActionValidator{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(createValidScheduledBackup()).Build(),
+		RunnableKinds: testRunnableKinds,
+	}
+
+	//
+	// ASSERT: existing ScheduledBackup, restore with a version
+	//
+	action := &v1alpha1.RequestedBackupAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1-restore", Namespace: "default"},
+		Spec: v1alpha1.RequestedBackupActionSpec{
+			Action:             "restore",
+			TargetVersion:      "v3",
+			ScheduledBackupRef: v1alpha1.BackupRefSpec{Name: "app1"},
+		},
+	}
+	assert.Nil(t, validator.ValidateCreate(context.TODO(), action))
+
+	//
+	// ASSERT: not existing ScheduledBackup, backup with a version
+	//
+	action.Spec.Action = "backup"
+	action.Spec.ScheduledBackupRef.Name = "app2"
+	err := validator.ValidateCreate(context.TODO(), action)
+
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "spec.targetVersion: Forbidden")
+	assert.Contains(t, err.Error(), "spec.scheduledBackupRef.name: Not found: \"app2\"")
+
+	//
+	// ASSERT: symbolic version with a typo
+	//
+	action.Spec.Action = "restore"
+	action.Spec.ScheduledBackupRef.Name = "app1"
+	action.Spec.TargetVersion = "latest-before: yesterday"
+	err = validator.ValidateCreate(context.TODO(), action)
+
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "spec.targetVersion: Invalid value: \"latest-before: yesterday\"")
+
+	//
+	// ASSERT: retry policy cannot retry a successful attempt
+	//
+	action.Spec.TargetVersion = ""
+	action.Spec.ScheduledBackupRef.Name = "app1"
+	action.Spec.RetryPolicy = &v1alpha1.RetryPolicySpec{MaxAttempts: 0, OnlyOn: []v1alpha1.RequestedBackupActionPhase{v1alpha1.PhaseSucceeded}}
+	err = validator.ValidateCreate(context.TODO(), action)
+
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "spec.retryPolicy.maxAttempts: Invalid value: 0")
+	assert.Contains(t, err.Error(), "spec.retryPolicy.onlyOn[0]: Unsupported value: \"Succeeded\"")
+
+	//
+	// ASSERT: Argo Workflows integration is not enabled in the controller
+	//
+	action.Spec.RetryPolicy = nil
+	action.Spec.KindType = v1alpha1.KindTypeWorkflow
+	err = validator.ValidateCreate(context.TODO(), action)
+
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "spec.kindType: Unsupported value: \"Workflow\": supported values: \"Job\", \"Pod\"")
+}
+
+func TestRequestedBackupActionValidator_RestoreTarget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+
+	// "dev" is allowed to do everything in "staging", but nothing in "production"
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		review.Status.Allowed = review.Spec.User == "dev" && review.Spec.ResourceAttributes.Namespace == "staging"
+		return true, review, nil
+	})
+	validator := RequestedBackupActionValidator{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(createValidScheduledBackup()).Build(),
+		Authorizer:    clientset.AuthorizationV1().SubjectAccessReviews(),
+		RunnableKinds: testRunnableKinds,
+	}
+	ctx := admission.NewContextWithRequest(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		UserInfo: authenticationv1.UserInfo{Username: "dev"},
+	}})
+
+	action := &v1alpha1.RequestedBackupAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1-clone", Namespace: "default"},
+		Spec: v1alpha1.RequestedBackupActionSpec{
+			Action:             "restore",
+			ScheduledBackupRef: v1alpha1.BackupRefSpec{Name: "app1"},
+			RestoreTarget: &v1alpha1.RestoreTargetSpec{
+				Namespace: "staging",
+				Vars:      "Params:\n    hostname: postgres.staging.svc\n",
+			},
+		},
+	}
+	assert.Nil(t, validator.ValidateCreate(ctx, action))
+
+	//
+	// ASSERT: user cannot restore into a namespace, where it could not create the objects by itself
+	//
+	action.Spec.RestoreTarget.Namespace = "production"
+	err := validator.ValidateCreate(ctx, action)
+
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "spec.restoreTarget.namespace: Forbidden: user 'dev' cannot create requestedbackupactions in namespace 'production'")
+	assert.Contains(t, err.Error(), "spec.restoreTarget.namespace: Forbidden: user 'dev' cannot create secrets in namespace 'production'")
+
+	//
+	// ASSERT: target is allowed only for a restore, vars are validated
+	//
+	action.Spec.Action = "backup"
+	action.Spec.RestoreTarget = &v1alpha1.RestoreTargetSpec{Vars: "Params: [invalid"}
+	err = validator.ValidateCreate(ctx, action)
+
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "spec.restoreTarget: Forbidden")
+	assert.Contains(t, err.Error(), "spec.restoreTarget.vars: Invalid value")
+}
+
+func TestRequestedBackupActionValidator_UpdateOfMetadataOnly(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Nil(t, v1alpha1.AddToScheme(scheme))
+	// the ScheduledBackup is already deleted, and the restore target would not pass the permissions check anymore
+	validator := RequestedBackupActionValidator{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	action := &v1alpha1.RequestedBackupAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "app1-clone",
+			Namespace:  "default",
+			Finalizers: []string{"riotkit.org/children-in-other-namespaces"},
+		},
+		Spec: v1alpha1.RequestedBackupActionSpec{
+			Action:             "restore",
+			ScheduledBackupRef: v1alpha1.BackupRefSpec{Name: "app1"},
+			RestoreTarget:      &v1alpha1.RestoreTargetSpec{Namespace: "staging"},
+		},
+	}
+	withoutFinalizer := action.DeepCopy()
+	withoutFinalizer.Finalizers = nil
+
+	//
+	// ASSERT: the finalizer can be removed, when the action is being deleted
+	//
+	withoutFinalizer.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.Nil(t, validator.ValidateUpdate(context.TODO(), action, withoutFinalizer))
+
+	//
+	// ASSERT: metadata can be changed also without deletion, only a changed spec is validated
+	//
+	withoutFinalizer.DeletionTimestamp = nil
+	assert.Nil(t, validator.ValidateUpdate(context.TODO(), action, withoutFinalizer))
+
+	withoutFinalizer.Spec.TargetVersion = "v3"
+	withoutFinalizer.Spec.RestoreTarget = nil
+	err := validator.ValidateUpdate(context.TODO(), action, withoutFinalizer)
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "spec.scheduledBackupRef.name: Not found: \"app1\"")
+}
+
+func TestScheduledBackupDefaulter(t *testing.T) {
+	backup := createValidScheduledBackup()
+	backup.Spec.GPGKeySecretRef = v1alpha1.GPGKeySecretSpec{SecretName: "backup-keys", PublicKey: "custom.pub"}
+	backup.Spec.CronJob.ScheduleEvery = ""
+	hashBeforeDefaulting := backup.Spec.CalculateHash()
+
+	assert.Nil(t, (&ScheduledBackupDefaulter{}).Default(context.TODO(), backup))
+
+	assert.Equal(t, "key", backup.Spec.GPGKeySecretRef.PrivateKey)
+	assert.Equal(t, "custom.pub", backup.Spec.GPGKeySecretRef.PublicKey, "Expected that explicitly set value will be kept")
+	assert.Equal(t, "passphrase", backup.Spec.GPGKeySecretRef.PassphraseKey)
+	assert.Equal(t, "00 02 * * *", backup.Spec.CronJob.ScheduleEvery)
+	assert.Equal(t, hashBeforeDefaulting, backup.Spec.CalculateHash(), "Persisting defaults should not be considered as a spec change")
+}
+
+func TestRequestedBackupActionDefaulter(t *testing.T) {
+	action := &v1alpha1.RequestedBackupAction{Spec: v1alpha1.RequestedBackupActionSpec{Action: "backup"}}
+
+	assert.Nil(t, (&RequestedBackupActionDefaulter{}).Default(context.TODO(), action))
+	assert.Equal(t, "Job", action.Spec.KindType)
+	assert.Nil(t, action.Spec.RetryPolicy, "Expected that retries are not enabled by default")
+
+	action.Spec.RetryPolicy = &v1alpha1.RetryPolicySpec{MaxAttempts: 3}
+	assert.Nil(t, (&RequestedBackupActionDefaulter{}).Default(context.TODO(), action))
+	assert.Equal(t, time.Second*30, action.Spec.RetryPolicy.Backoff.Duration)
+	assert.Equal(t, []v1alpha1.RequestedBackupActionPhase{v1alpha1.PhaseFailed, v1alpha1.PhaseTimedOut}, action.Spec.RetryPolicy.OnlyOn)
+}